@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/romeufcrosa/where-to-eat/config"
+	"github.com/romeufcrosa/where-to-eat/gateways/local"
+)
+
+// runCatalogue handles the import and export subcommands of the offline catalogue
+func runCatalogue(command string, args []string) {
+	settings, err := config.Read(config.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	path := flags.String("catalogue", settings.Catalogue.Path, "path of the offline catalogue")
+	format := flags.String("format", "", "json or csv, guessed from the file extension when empty")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] <file>\n", os.Args[0], command)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	file := flags.Arg(0)
+	if *format == "" {
+		*format = local.FormatFrom(file)
+	}
+
+	catalogue, err := local.Open(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if command == "import" {
+		importCatalogue(catalogue, file, *format)
+		return
+	}
+	exportCatalogue(catalogue, file, *format)
+}
+
+func importCatalogue(catalogue *local.Catalogue, file, format string) {
+	input, err := os.Open(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer input.Close()
+
+	count, err := catalogue.Import(input, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := catalogue.Save(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Imported %d places from %s", count, file)
+}
+
+func exportCatalogue(catalogue *local.Catalogue, file, format string) {
+	output, err := os.Create(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := catalogue.Export(output, format); err != nil {
+		output.Close()
+		log.Fatal(err)
+	}
+	if err := output.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Exported catalogue to %s", file)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+
+	"github.com/romeufcrosa/where-to-eat/config"
+	"github.com/romeufcrosa/where-to-eat/gateways/google"
+	"github.com/romeufcrosa/where-to-eat/gateways/nominatim"
+	"github.com/romeufcrosa/where-to-eat/gateways/wifi"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"github.com/romeufcrosa/where-to-eat/domain/services"
+)
+
+// newResolver returns the resolver trying, in order, the location given, Wi-Fi,
+// the IP address and the saved home
+func newResolver(settings config.Config, locator services.Locate) (services.Resolver, error) {
+	var sources []services.NamedSource
+
+	if at := settings.Location.At; at != "" {
+		var source services.PositionSource = addressSource(locator, at)
+		if location, err := domain.ParseLocation(at); err == nil {
+			source = services.FixedPosition{Location: location}
+		}
+		sources = append(sources, services.NamedSource{Name: services.GivenSource, Source: source})
+	}
+
+	sources = append(sources,
+		services.NamedSource{Name: services.WifiSource, Source: wifiSource(settings.Wifi, locator)},
+		services.NamedSource{Name: services.IPSource, Source: services.PositionFunc(locator.FetchIPLocation)},
+	)
+
+	if home := settings.Location.Home; home != "" {
+		location, err := domain.ParseLocation(home)
+		if err != nil {
+			return services.Resolver{}, err
+		}
+		sources = append(sources, services.NamedSource{
+			Name:   services.HomeSource,
+			Source: services.FixedPosition{Location: location, Accuracy: settings.Location.HomeAccuracy},
+		})
+	}
+
+	return services.NewResolver(settings.Location.GoodEnough, sources...), nil
+}
+
+// wifiSource locates from the access points scanned, or replayed
+func wifiSource(settings config.Wifi, locator services.Locate) services.PositionFunc {
+	return func(ctx context.Context) (domain.Position, error) {
+		scanner, err := scannerFor(settings)
+		if err != nil {
+			return domain.Position{}, err
+		}
+		accessPoints, err := scanner.Scan(ctx)
+		if err != nil {
+			return domain.Position{}, err
+		}
+		return locator.FetchLocation(ctx, accessPoints)
+	}
+}
+
+// scannerFor returns the replay of a captured scan when one is set, the
+// scanner of this system otherwise
+func scannerFor(settings config.Wifi) (wifi.Scanner, error) {
+	if settings.Replay == "" {
+		return wifi.NewScanner(), nil
+	}
+	return wifi.NewReplay(settings.Replay, wifi.Format(settings.ReplayFormat))
+}
+
+// addressSource locates the address given
+func addressSource(locator services.Locate, address string) services.PositionFunc {
+	return func(ctx context.Context) (domain.Position, error) {
+		match, err := locator.Geocode(ctx, address)
+		if err != nil {
+			return domain.Position{}, err
+		}
+		return match.Position, nil
+	}
+}
+
+// geocoderFor returns the geocoder selected in the settings
+func geocoderFor(settings config.Geocoding, googleGateway *google.GeoGateway) services.Geocoder {
+	if settings.Provider == config.NominatimProvider {
+		nominatimGateway := nominatim.NewGateway(settings.NominatimEndpoint, nil)
+		return &nominatimGateway
+	}
+	return googleGateway
+}
@@ -0,0 +1,472 @@
+// Package config loads the service settings from defaults, a YAML file,
+// environment variables and command line flags, in increasing precedence
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Places providers a configuration can select
+const (
+	GoogleProvider = "gateways/google"
+	OSMProvider    = "gateways/osm"
+	LocalProvider  = "gateways/local"
+)
+
+// NominatimProvider geocoding provider a configuration can select besides Google
+const NominatimProvider = "gateways/nominatim"
+
+const redacted = "[redacted]"
+
+// Secret a setting that must never be printed, such as an API key
+type Secret string
+
+// String returns a redacted representation of the secret
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString returns a redacted representation of the secret for %#v
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// MarshalYAML writes the secret redacted
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// Reveal returns the actual value of the secret
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// Server HTTP server settings
+type Server struct {
+	Port string `yaml:"port"`
+	// RequestTimeout deadline of a request, upstream calls are cancelled past it
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+}
+
+// Google Google Maps Platform settings
+type Google struct {
+	APIKey   Secret `yaml:"api_key"`
+	MaxPages int    `yaml:"max_pages"`
+	// QPS calls per second allowed on each endpoint
+	QPS float64 `yaml:"qps"`
+	// DailyQuota calls per day allowed on an endpoint, unlimited when missing
+	DailyQuota map[string]int `yaml:"daily_quota"`
+	// Timeouts deadline of a single call to an endpoint
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+}
+
+// GoogleEndpoints the Google endpoints calls are budgeted for
+var GoogleEndpoints = []string{"nearby", "details", "geolocate", "geocode"}
+
+// Bugsnag error reporting settings
+type Bugsnag struct {
+	APIKey       Secret `yaml:"api_key"`
+	ReleaseStage string `yaml:"release_stage"`
+}
+
+// Places restaurant search settings
+type Places struct {
+	Provider string `yaml:"provider"`
+	// Fallback provider searched while Provider is unavailable, none when empty
+	Fallback string `yaml:"fallback"`
+}
+
+// OSM OpenStreetMap settings
+type OSM struct {
+	Endpoint string `yaml:"endpoint"`
+}
+
+// Geocoding address and place name search settings
+type Geocoding struct {
+	Provider string `yaml:"provider"`
+	// NominatimEndpoint base URL of the Nominatim instance, the public one when empty
+	NominatimEndpoint string `yaml:"nominatim_endpoint"`
+	// NominatimUserAgent and NominatimEmail identify the deployment to the
+	// Nominatim operators, as the public instance's usage policy asks
+	NominatimUserAgent string `yaml:"nominatim_user_agent"`
+	NominatimEmail     string `yaml:"nominatim_email"`
+}
+
+// Catalogue offline catalogue settings
+type Catalogue struct {
+	Path string `yaml:"path"`
+}
+
+// Cache backends a configuration can select
+const (
+	NoCache     = "none"
+	MemoryCache = "memory"
+	RedisCache  = "redis"
+)
+
+// Cache nearby search cache settings
+type Cache struct {
+	Backend string        `yaml:"backend"`
+	TTL     time.Duration `yaml:"ttl"`
+	// Size entries kept by the memory backend
+	Size int `yaml:"size"`
+	// Precision geohash length of the cells searches are shared in
+	Precision int `yaml:"precision"`
+	// RedisAddress host:port of the redis backend
+	RedisAddress string `yaml:"redis_address"`
+}
+
+// Resilience retry and circuit breaker settings of upstream calls
+type Resilience struct {
+	Attempts         int           `yaml:"attempts"`
+	BaseDelay        time.Duration `yaml:"base_delay"`
+	MaxDelay         time.Duration `yaml:"max_delay"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
+// Wifi access point scanning settings of the CLI
+type Wifi struct {
+	// Replay path of a captured scan used instead of scanning, when set
+	Replay string `yaml:"replay"`
+	// ReplayFormat the tool the replay was captured with, or json
+	ReplayFormat string `yaml:"replay_format"`
+}
+
+// Location how the CLI finds where the user is
+type Location struct {
+	// At "lat,lng" or an address used instead of locating, when set
+	At string `yaml:"at"`
+	// Home "lat,lng" of a saved place, used when nothing more accurate is found
+	Home string `yaml:"home"`
+	// HomeAccuracy meters the saved place is trusted within
+	HomeAccuracy float64 `yaml:"home_accuracy"`
+	// GoodEnough accuracy in meters past which no other source is asked
+	GoodEnough float64 `yaml:"good_enough"`
+}
+
+// WifiFormats the formats a Wi-Fi scan can be replayed from
+var WifiFormats = []string{"json", "nmcli", "iw", "airport", "netsh"}
+
+// Config the service settings
+type Config struct {
+	Server     Server     `yaml:"server"`
+	Google     Google     `yaml:"google"`
+	Bugsnag    Bugsnag    `yaml:"bugsnag"`
+	Places     Places     `yaml:"places"`
+	OSM        OSM        `yaml:"osm"`
+	Geocoding  Geocoding  `yaml:"geocoding"`
+	Catalogue  Catalogue  `yaml:"catalogue"`
+	Cache      Cache      `yaml:"cache"`
+	Resilience Resilience `yaml:"resilience"`
+	Wifi       Wifi       `yaml:"wifi"`
+	Location   Location   `yaml:"location"`
+}
+
+// Defaults returns the settings used when nothing else is set
+func Defaults() Config {
+	return Config{
+		Server: Server{Port: "8080", RequestTimeout: 9 * time.Second},
+		Google: Google{
+			MaxPages: 3,
+			QPS:      50,
+			Timeouts: map[string]time.Duration{
+				"nearby":    5 * time.Second,
+				"details":   3 * time.Second,
+				"geolocate": 3 * time.Second,
+				"geocode":   3 * time.Second,
+			},
+		},
+		Bugsnag:   Bugsnag{ReleaseStage: "production"},
+		Places:    Places{Provider: GoogleProvider},
+		Geocoding: Geocoding{Provider: GoogleProvider},
+		Catalogue: Catalogue{Path: "catalogue.json"},
+		Cache:     Cache{Backend: MemoryCache, TTL: 10 * time.Minute, Size: 256, Precision: 7},
+		Resilience: Resilience{
+			Attempts:         3,
+			BaseDelay:        100 * time.Millisecond,
+			MaxDelay:         2 * time.Second,
+			FailureThreshold: 5,
+			Cooldown:         30 * time.Second,
+		},
+		Wifi:     Wifi{ReplayFormat: "json"},
+		Location: Location{HomeAccuracy: 1000, GoodEnough: 250},
+	}
+}
+
+// String returns the settings as YAML with secrets redacted, safe to log
+func (c Config) String() string {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (v ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(v.Problems, "; ")
+}
+
+// Validate checks the settings are usable
+func (c Config) Validate() error {
+	var problems []string
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port %q is not a valid port", c.Server.Port))
+	}
+
+	if c.Server.RequestTimeout <= 0 {
+		problems = append(problems, "server.request_timeout must be positive")
+	}
+
+	switch c.Places.Provider {
+	case GoogleProvider:
+		if c.Google.APIKey == "" {
+			problems = append(problems, "google.api_key is required by the "+GoogleProvider+" places provider")
+		}
+	case OSMProvider, LocalProvider:
+	default:
+		problems = append(problems, fmt.Sprintf("places.provider %q is unknown", c.Places.Provider))
+	}
+
+	switch c.Places.Fallback {
+	case "", GoogleProvider, OSMProvider, LocalProvider:
+	default:
+		problems = append(problems, fmt.Sprintf("places.fallback %q is unknown", c.Places.Fallback))
+	}
+
+	switch c.Geocoding.Provider {
+	case GoogleProvider, NominatimProvider:
+	default:
+		problems = append(problems, fmt.Sprintf("geocoding.provider %q is unknown", c.Geocoding.Provider))
+	}
+
+	if c.Google.MaxPages < 1 || c.Google.MaxPages > 3 {
+		problems = append(problems, "google.max_pages must be between 1 and 3")
+	}
+
+	if c.Google.QPS <= 0 {
+		problems = append(problems, "google.qps must be positive")
+	}
+
+	for endpoint, quota := range c.Google.DailyQuota {
+		if !knownEndpoint(endpoint) {
+			problems = append(problems, fmt.Sprintf("google.daily_quota has unknown endpoint %q, expected one of %s", endpoint, strings.Join(GoogleEndpoints, ", ")))
+		} else if quota < 0 {
+			problems = append(problems, fmt.Sprintf("google.daily_quota.%s must not be negative", endpoint))
+		}
+	}
+
+	for endpoint, timeout := range c.Google.Timeouts {
+		if !knownEndpoint(endpoint) {
+			problems = append(problems, fmt.Sprintf("google.timeouts has unknown endpoint %q, expected one of %s", endpoint, strings.Join(GoogleEndpoints, ", ")))
+		} else if timeout <= 0 {
+			problems = append(problems, fmt.Sprintf("google.timeouts.%s must be positive", endpoint))
+		}
+	}
+
+	if c.Places.Provider == LocalProvider && c.Catalogue.Path == "" {
+		problems = append(problems, "catalogue.path is required by the "+LocalProvider+" places provider")
+	}
+
+	switch c.Cache.Backend {
+	case NoCache, MemoryCache:
+	case RedisCache:
+		if c.Cache.RedisAddress == "" {
+			problems = append(problems, "cache.redis_address is required by the "+RedisCache+" cache backend")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("cache.backend %q is unknown", c.Cache.Backend))
+	}
+
+	if c.Cache.Backend != NoCache && (c.Cache.TTL <= 0 || c.Cache.Size < 1 || c.Cache.Precision < 1 || c.Cache.Precision > 12) {
+		problems = append(problems, "cache.ttl and cache.size must be positive and cache.precision between 1 and 12")
+	}
+
+	if c.Resilience.Attempts < 1 || c.Resilience.FailureThreshold < 1 {
+		problems = append(problems, "resilience.attempts and resilience.failure_threshold must be positive")
+	}
+
+	if !oneOf(c.Wifi.ReplayFormat, WifiFormats) {
+		problems = append(problems, fmt.Sprintf("wifi.replay_format %q is unknown, expected one of %s", c.Wifi.ReplayFormat, strings.Join(WifiFormats, ", ")))
+	}
+
+	if c.Location.HomeAccuracy < 0 || c.Location.GoodEnough < 0 {
+		problems = append(problems, "location.home_accuracy and location.good_enough must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func knownEndpoint(endpoint string) bool {
+	return oneOf(endpoint, GoogleEndpoints)
+}
+
+func oneOf(value string, values []string) bool {
+	for _, known := range values {
+		if value == known {
+			return true
+		}
+	}
+	return false
+}
+
+// setting binds one configuration value to its environment variable and flag
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	value func(c *Config) *string
+}
+
+var settings = []setting{
+	{"PORT", "port", "port the API listens on", func(c *Config) *string { return &c.Server.Port }},
+	{"GOOGLE_API_KEY", "google-api-key", "Google Maps Platform API key", func(c *Config) *string { return (*string)(&c.Google.APIKey) }},
+	{"BUGSNAG_API_KEY", "bugsnag-api-key", "Bugsnag notifier API key", func(c *Config) *string { return (*string)(&c.Bugsnag.APIKey) }},
+	{"BUGSNAG_RELEASE_STAGE", "bugsnag-release-stage", "Bugsnag release stage", func(c *Config) *string { return &c.Bugsnag.ReleaseStage }},
+	{"PLACES_PROVIDER", "places-provider", "provider answering restaurant searches", func(c *Config) *string { return &c.Places.Provider }},
+	{"PLACES_FALLBACK", "places-fallback", "provider searched while the places provider is unavailable", func(c *Config) *string { return &c.Places.Fallback }},
+	{"OVERPASS_ENDPOINT", "overpass-endpoint", "OpenStreetMap Overpass interpreter URL", func(c *Config) *string { return &c.OSM.Endpoint }},
+	{"GEOCODING_PROVIDER", "geocoding-provider", "provider searching addresses and place names", func(c *Config) *string { return &c.Geocoding.Provider }},
+	{"NOMINATIM_ENDPOINT", "nominatim-endpoint", "Nominatim instance URL", func(c *Config) *string { return &c.Geocoding.NominatimEndpoint }},
+	{"NOMINATIM_USER_AGENT", "nominatim-user-agent", "User-Agent identifying this deployment to Nominatim", func(c *Config) *string { return &c.Geocoding.NominatimUserAgent }},
+	{"NOMINATIM_EMAIL", "nominatim-email", "contact email sent along with Nominatim requests", func(c *Config) *string { return &c.Geocoding.NominatimEmail }},
+	{"CATALOGUE_PATH", "catalogue", "path of the offline catalogue", func(c *Config) *string { return &c.Catalogue.Path }},
+	{"CACHE_BACKEND", "cache", "nearby search cache backend", func(c *Config) *string { return &c.Cache.Backend }},
+	{"REDIS_ADDRESS", "redis-address", "address of the redis cache", func(c *Config) *string { return &c.Cache.RedisAddress }},
+	{"WIFI_REPLAY", "wifi-replay", "captured Wi-Fi scan located from instead of scanning", func(c *Config) *string { return &c.Wifi.Replay }},
+	{"WIFI_REPLAY_FORMAT", "wifi-replay-format", "format of the captured Wi-Fi scan", func(c *Config) *string { return &c.Wifi.ReplayFormat }},
+	{"LOCATION_AT", "at", "coordinates as lat,lng or an address to search around instead of locating", func(c *Config) *string { return &c.Location.At }},
+	{"LOCATION_HOME", "home", "coordinates as lat,lng of a saved place searched around when locating fails", func(c *Config) *string { return &c.Location.Home }},
+}
+
+// intSetting binds an integer configuration value to its environment variable and flag
+type intSetting struct {
+	env   string
+	flag  string
+	usage string
+	value func(c *Config) *int
+}
+
+var intSettings = []intSetting{
+	{"GOOGLE_MAX_PAGES", "google-max-pages", "Nearby Search pages followed per search", func(c *Config) *int { return &c.Google.MaxPages }},
+}
+
+// Options where Load reads the settings from
+type Options struct {
+	// Path of the YAML file, overridden by the CONFIG_FILE environment variable and the -config flag
+	Path string
+	// Args command line arguments, without the program name
+	Args []string
+	// Lookup reads environment variables, os.LookupEnv when nil
+	Lookup func(key string) (string, bool)
+}
+
+// Load reads the settings, validating the result
+func Load(options Options) (Config, error) {
+	config, err := Read(options)
+	if err != nil {
+		return config, err
+	}
+
+	return config, config.Validate()
+}
+
+// Read reads the settings without validating them, for tools needing only a few
+func Read(options Options) (Config, error) {
+	config, _, err := read(options)
+	return config, err
+}
+
+// read reads the settings along with the path of the file they came from
+func read(options Options) (Config, string, error) {
+	lookup := options.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	flags := flag.NewFlagSet("where-to-eat", flag.ContinueOnError)
+	path := flags.String("config", options.Path, "path of the YAML configuration file")
+	flagValues := make(map[string]*string)
+	for _, s := range settings {
+		flagValues[s.flag] = flags.String(s.flag, "", s.usage)
+	}
+	intFlagValues := make(map[string]*int)
+	for _, s := range intSettings {
+		intFlagValues[s.flag] = flags.Int(s.flag, 0, s.usage)
+	}
+	if err := flags.Parse(options.Args); err != nil {
+		return Config{}, "", err
+	}
+
+	explicit := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	if env, ok := lookup("CONFIG_FILE"); ok && !explicit["config"] {
+		*path = env
+	}
+
+	config := Defaults()
+	if *path != "" {
+		if err := config.merge(*path); err != nil {
+			return Config{}, "", err
+		}
+	}
+
+	for _, s := range settings {
+		if env, ok := lookup(s.env); ok && env != "" {
+			*s.value(&config) = env
+		}
+		if explicit[s.flag] {
+			*s.value(&config) = *flagValues[s.flag]
+		}
+	}
+	for _, s := range intSettings {
+		if env, ok := lookup(s.env); ok && env != "" {
+			value, err := strconv.Atoi(env)
+			if err != nil {
+				return Config{}, "", fmt.Errorf("%s must be a number", s.env)
+			}
+			*s.value(&config) = value
+		}
+		if explicit[s.flag] {
+			*s.value(&config) = *intFlagValues[s.flag]
+		}
+	}
+
+	return config, *path, nil
+}
+
+// merge overrides the settings with the ones present in the YAML file at path
+func (c *Config) merge(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return errors.New("config file " + path + ": " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func environment(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  port: "9000"
+google:
+  api_key: from-file
+places:
+  provider: gateways/osm
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	testCases := []struct {
+		desc             string
+		env              map[string]string
+		args             []string
+		expectedPort     string
+		expectedKey      string
+		expectedProvider string
+	}{
+		{
+			desc:             "File overrides defaults",
+			expectedPort:     "9000",
+			expectedKey:      "from-file",
+			expectedProvider: OSMProvider,
+		},
+		{
+			desc:             "Environment overrides the file",
+			env:              map[string]string{"PORT": "5000", "GOOGLE_API_KEY": "from-env"},
+			expectedPort:     "5000",
+			expectedKey:      "from-env",
+			expectedProvider: OSMProvider,
+		},
+		{
+			desc:             "Flags override the environment",
+			env:              map[string]string{"PORT": "5000", "PLACES_PROVIDER": LocalProvider},
+			args:             []string{"-port", "7000", "-places-provider", GoogleProvider},
+			expectedPort:     "7000",
+			expectedKey:      "from-file",
+			expectedProvider: GoogleProvider,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			config, err := Load(Options{Path: path, Args: tC.args, Lookup: environment(tC.env)})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Server.Port).To(Equal(tC.expectedPort))
+			Expect(config.Google.APIKey.Reveal()).To(Equal(tC.expectedKey))
+			Expect(config.Places.Provider).To(Equal(tC.expectedProvider))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := Load(Options{
+		Args:   []string{"-port", "http", "-google-max-pages", "9"},
+		Lookup: environment(nil),
+	})
+
+	Expect(err).To(BeAssignableToTypeOf(ValidationError{}))
+	Expect(err.(ValidationError).Problems).To(HaveLen(3), "every problem should be reported at once")
+
+	typo := writeConfig(t, "google:\n  api_kye: typo\n")
+	defer os.RemoveAll(filepath.Dir(typo))
+	_, err = Load(Options{Path: typo, Lookup: environment(nil)})
+	Expect(err).To(MatchError(ContainSubstring("api_kye")), "unknown keys should be rejected")
+
+	unknownEndpoint := writeConfig(t, "google:\n  daily_quota:\n    nearby: 100\n    directions: 10\n")
+	defer os.RemoveAll(filepath.Dir(unknownEndpoint))
+	_, err = Load(Options{
+		Path:   unknownEndpoint,
+		Args:   []string{"-places-provider", OSMProvider},
+		Lookup: environment(nil),
+	})
+	Expect(err).To(MatchError(ContainSubstring(`unknown endpoint "directions"`)))
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	RegisterTestingT(t)
+	config := Defaults()
+	config.Google.APIKey = "AIza-very-secret"
+	config.Bugsnag.APIKey = "bugsnag-secret"
+
+	for _, printed := range []string{
+		config.String(),
+		fmt.Sprintf("%v", config),
+		fmt.Sprintf("%+v", config.Google),
+		fmt.Sprintf("%#v", config.Bugsnag),
+	} {
+		Expect(printed).NotTo(ContainSubstring("secret"))
+		Expect(printed).To(ContainSubstring(redacted))
+	}
+}
+
+func TestLoaderReload(t *testing.T) {
+	RegisterTestingT(t)
+	path := writeConfig(t, "google:\n  api_key: first\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	loader, err := NewLoader(Options{Path: path, Lookup: environment(nil)})
+	Expect(err).NotTo(HaveOccurred())
+	updated := loader.UpdateFunc()
+
+	changed, err := loader.Reload()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(changed).To(BeFalse())
+	Expect(updated()).To(BeFalse())
+
+	Expect(ioutil.WriteFile(path, []byte("google:\n  api_key: second\n"), 0600)).To(Succeed())
+	changed, err = loader.Reload()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(changed).To(BeTrue())
+	Expect(updated()).To(BeTrue())
+	Expect(updated()).To(BeFalse(), "a change should only be reported once")
+	Expect(loader.Current().Google.APIKey.Reveal()).To(Equal("second"))
+
+	Expect(ioutil.WriteFile(path, []byte("server:\n  port: nope\n"), 0600)).To(Succeed())
+	_, err = loader.Reload()
+	Expect(err).To(HaveOccurred())
+	Expect(loader.Current().Google.APIKey.Reveal()).To(Equal("second"), "invalid settings should be rejected")
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Loader holds the current settings and reloads them on demand
+type Loader struct {
+	options Options
+	path    string
+
+	mu         sync.RWMutex
+	current    Config
+	generation uint64
+}
+
+// NewLoader loads the settings a first time, failing when they are invalid
+func NewLoader(options Options) (*Loader, error) {
+	config, path, err := read(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Loader{
+		options: options,
+		path:    path,
+		current: config,
+	}, nil
+}
+
+// Path returns the file the settings are read from, empty when there is none
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// Current returns the settings in use
+func (l *Loader) Current() Config {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.current
+}
+
+// Reload reads the settings again, returning whether they changed. Invalid
+// settings are rejected and the current ones kept
+func (l *Loader) Reload() (bool, error) {
+	config, err := Load(l.options)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if reflect.DeepEqual(config, l.current) {
+		return false, nil
+	}
+
+	l.current = config
+	l.generation++
+	return true, nil
+}
+
+// UpdateFunc returns a function reporting, once per change, whether the settings
+// changed since it last looked. Each caller should get its own
+func (l *Loader) UpdateFunc() func() bool {
+	l.mu.RLock()
+	seen := l.generation
+	l.mu.RUnlock()
+
+	var mu sync.Mutex
+	return func() bool {
+		l.mu.RLock()
+		generation := l.generation
+		l.mu.RUnlock()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if generation == seen {
+			return false
+		}
+		seen = generation
+		return true
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// ReloadFunc is told about every reload a watch triggers
+type ReloadFunc func(changed bool, err error)
+
+// Watch polls the settings file every interval and reloads the settings when it
+// is modified, until ctx is done. Without a settings file there is nothing to watch
+func (l *Loader) Watch(ctx context.Context, interval time.Duration, reloaded ReloadFunc) {
+	if l.path == "" {
+		return
+	}
+
+	last := fingerprint(l.path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := fingerprint(l.path)
+			if current == last {
+				continue
+			}
+			last = current
+
+			changed, err := l.Reload()
+			reloaded(changed, err)
+		}
+	}
+}
+
+type fileFingerprint struct {
+	modified time.Time
+	size     int64
+}
+
+func fingerprint(path string) fileFingerprint {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileFingerprint{}
+	}
+	return fileFingerprint{modified: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,174 @@
+package entities
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewFromJSON decodes and validates a search from the request body, the error
+// listing every problem found as a ValidationError
+func NewFromJSON(bodyBytes []byte) (sr SearchRequest, err error) {
+	var v violations
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(bodyBytes, &object); err != nil || object == nil {
+		v.add("body", "must be a JSON object")
+		return sr, v.err()
+	}
+
+	return bindSearch(object, &v)
+}
+
+// NewFromQuery decodes and validates a search from query parameters, such as
+// lat=38.71&lng=-9.13&distance=500&pricing=1-2, with the same rules as NewFromJSON
+func NewFromQuery(values url.Values) (sr SearchRequest, err error) {
+	var v violations
+
+	object := make(map[string]json.RawMessage)
+	for name, value := range values {
+		if len(value) > 1 {
+			v.add(name, "must be given once")
+			continue
+		}
+		object[name] = jsonValue(name, value[0])
+	}
+
+	return bindSearch(object, &v)
+}
+
+// jsonValue returns a query value as the JSON its field expects: numbers and
+// booleans as they are when they parse, anything else as a string so a value
+// of the wrong type is reported as such
+func jsonValue(name, value string) json.RawMessage {
+	switch kindOf(searchFields[name]) {
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.RawMessage(value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return json.RawMessage(value)
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return json.RawMessage(strconv.FormatBool(b))
+		}
+	}
+
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
+
+// kindOf returns the kind of a field type, looking through pointers
+func kindOf(t reflect.Type) reflect.Kind {
+	if t == nil {
+		return reflect.Invalid
+	}
+	if t.Kind() == reflect.Ptr {
+		return t.Elem().Kind()
+	}
+	return t.Kind()
+}
+
+// requiredFields fields a search can't do without, zero being a valid value for some
+var requiredFields = []string{"lat", "lng", "distance"}
+
+// locationFields required fields near stands in for
+var locationFields = map[string]bool{"lat": true, "lng": true}
+
+// searchFields the type of every JSON field of a SearchRequest
+var searchFields = jsonFields(reflect.TypeOf(SearchRequest{}))
+
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" && name != "-" {
+			fields[name] = t.Field(i).Type
+		}
+	}
+	return fields
+}
+
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// bindSearch decodes a search from the fields of a JSON object, reporting
+// unknown and missing fields along with those of the wrong type, then validates it
+func bindSearch(object map[string]json.RawMessage, v *violations) (sr SearchRequest, err error) {
+	var unknown []string
+	for field := range object {
+		if _, ok := searchFields[field]; !ok {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		v.add(field, "is not a known field")
+	}
+
+	given := func(field string) bool {
+		value, ok := object[field]
+		return ok && string(value) != "null"
+	}
+	for _, field := range requiredFields {
+		if !given(field) && !(given("near") && locationFields[field]) {
+			v.add(field, "is required")
+		}
+	}
+
+	// fields are decoded one at a time so every one of the wrong type is reported
+	decoded := reflect.ValueOf(&sr).Elem()
+	for i := 0; i < decoded.NumField(); i++ {
+		name := jsonName(decoded.Type().Field(i))
+		value, ok := object[name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, decoded.Field(i).Addr().Interface()); err != nil {
+			v.add(name, problemOf(err))
+		}
+	}
+
+	if given("near") && strings.TrimSpace(sr.Near) == "" {
+		v.add("near", "must not be blank")
+	}
+	if given("near") && (given("lat") || given("lng")) {
+		v.add("near", "must not be given along with lat and lng")
+	}
+
+	sr.validate(v)
+	return sr, v.err()
+}
+
+func problemOf(err error) string {
+	switch err := err.(type) {
+	case *json.UnmarshalTypeError:
+		return "must be a " + typeName(err.Type)
+	case *time.ParseError:
+		return "must be an RFC 3339 time"
+	}
+	return err.Error()
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "whole number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "non-negative whole number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	}
+	return t.String()
+}
@@ -0,0 +1,77 @@
+package entities
+
+// ErrorKind the category of a failure, telling callers how to react to it
+type ErrorKind string
+
+// Error kinds
+const (
+	// Unknown failures nothing is known about, most likely a bug
+	Unknown = ErrorKind("internal")
+	// Validation the request can't be served as it is
+	Validation = ErrorKind("validation")
+	// NotFound nothing matches the request
+	NotFound = ErrorKind("not_found")
+	// UpstreamUnavailable a service the request depends on is down, retrying later may work
+	UpstreamUnavailable = ErrorKind("upstream_unavailable")
+	// QuotaExceeded the daily budget of an upstream service is spent
+	QuotaExceeded = ErrorKind("quota_exceeded")
+)
+
+// KindError an error of a known kind
+type KindError struct {
+	Kind    ErrorKind
+	Message string
+	// Err the cause, if any
+	Err error
+}
+
+// NewError returns an error of the given kind
+func NewError(kind ErrorKind, message string) *KindError {
+	return &KindError{Kind: kind, Message: message}
+}
+
+// WrapError returns err as an error of the given kind, keeping its message
+func WrapError(kind ErrorKind, err error) *KindError {
+	return &KindError{Kind: kind, Err: err}
+}
+
+func (e *KindError) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// ErrorKind returns the kind of the error
+func (e *KindError) ErrorKind() ErrorKind {
+	return e.Kind
+}
+
+// Unwrap returns the cause of the error
+func (e *KindError) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the first known kind found walking err and its causes,
+// Unknown when none has one
+func KindOf(err error) ErrorKind {
+	for err != nil {
+		if kinded, ok := err.(interface{ ErrorKind() ErrorKind }); ok {
+			if kind := kinded.ErrorKind(); kind != "" && kind != Unknown {
+				return kind
+			}
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = wrapper.Unwrap()
+	}
+	return Unknown
+}
+
+// ErrQuotaExceeded error sent when an upstream API budget is used up for the day
+var ErrQuotaExceeded error = NewError(QuotaExceeded, "upstream quota exceeded for today")
@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// contextError adds context to an error the way callers outside this package do
+type contextError struct {
+	context string
+	err     error
+}
+
+func (e contextError) Error() string {
+	return e.context + ": " + e.err.Error()
+}
+
+func (e contextError) Unwrap() error {
+	return e.err
+}
+
+func TestKindOf(t *testing.T) {
+	RegisterTestingT(t)
+	cause := errors.New("connection refused")
+
+	testCases := []struct {
+		desc     string
+		err      error
+		expected ErrorKind
+	}{
+		{"Plain error", cause, Unknown},
+		{"No error", nil, Unknown},
+		{"Kinded error", WrapError(UpstreamUnavailable, cause), UpstreamUnavailable},
+		{"Wrapped without a kind", WrapError("", WrapError(NotFound, cause)), NotFound},
+		{"Wrapped as unknown", WrapError(Unknown, ErrQuotaExceeded), QuotaExceeded},
+		{"Outermost kind wins", WrapError(Validation, WrapError(NotFound, cause)), Validation},
+		{"Wrapped by a caller", contextError{"details of abc", WrapError(NotFound, cause)}, NotFound},
+		{"Deeply wrapped", contextError{"search", WrapError("", contextError{"page 2", ErrQuotaExceeded})}, QuotaExceeded},
+	}
+
+	for _, tc := range testCases {
+		Expect(KindOf(tc.err)).To(Equal(tc.expected), tc.desc)
+	}
+}
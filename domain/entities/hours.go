@@ -0,0 +1,244 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// ErrInvalidOpeningHours error sent when opening hours can't be understood
+var ErrInvalidOpeningHours = errors.New("invalid opening hours")
+
+// DayTime a moment of the week, Time is in 24-hour hhmm format such as "1930"
+type DayTime struct {
+	Day  time.Weekday `json:"day"`
+	Time string       `json:"time"`
+}
+
+// Period a span of time a place is open. A nil Close means the place never closes
+type Period struct {
+	Open  DayTime  `json:"open"`
+	Close *DayTime `json:"close,omitempty"`
+}
+
+// OpeningHours weekly opening periods of a place, in the place's time zone.
+// UTCOffset is the minutes that zone is ahead of UTC, when the source knows it
+type OpeningHours struct {
+	Periods   []Period `json:"periods"`
+	UTCOffset *int     `json:"utc_offset,omitempty"`
+}
+
+// IsOpenAt returns whether the place is open at the given instant, read in the
+// place's time zone when known and in the one of t otherwise
+func (h OpeningHours) IsOpenAt(t time.Time) bool {
+	if h.UTCOffset != nil {
+		t = t.In(time.FixedZone("", *h.UTCOffset*60))
+	}
+	now := int(t.Weekday())*minutesPerDay + t.Hour()*60 + t.Minute()
+
+	for _, period := range h.Periods {
+		open, err := period.Open.minuteOfWeek()
+		if err != nil {
+			continue
+		}
+		if period.Close == nil {
+			return true
+		}
+		close, err := period.Close.minuteOfWeek()
+		if err != nil {
+			continue
+		}
+		// Spans past Saturday midnight wrap around to the start of the week
+		if close <= open {
+			close += minutesPerWeek
+		}
+
+		if (now >= open && now < close) || (now+minutesPerWeek >= open && now+minutesPerWeek < close) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OpensOn returns the first opening time on the given day, empty when closed all day
+func (h OpeningHours) OpensOn(day time.Weekday) string {
+	for _, period := range h.Periods {
+		if period.Open.Day == day {
+			return period.Open.Time
+		}
+	}
+	return ""
+}
+
+func (d DayTime) minuteOfWeek() (int, error) {
+	if len(d.Time) != 4 || d.Day < time.Sunday || d.Day > time.Saturday {
+		return 0, ErrInvalidOpeningHours
+	}
+
+	hours, err := strconv.Atoi(d.Time[:2])
+	if err != nil || hours > 23 {
+		return 0, ErrInvalidOpeningHours
+	}
+	minutes, err := strconv.Atoi(d.Time[2:])
+	if err != nil || minutes > 59 {
+		return 0, ErrInvalidOpeningHours
+	}
+
+	return int(d.Day)*minutesPerDay + hours*60 + minutes, nil
+}
+
+var osmDays = map[string]time.Weekday{
+	"Su": time.Sunday,
+	"Mo": time.Monday,
+	"Tu": time.Tuesday,
+	"We": time.Wednesday,
+	"Th": time.Thursday,
+	"Fr": time.Friday,
+	"Sa": time.Saturday,
+}
+
+type span struct {
+	open  int
+	close int
+}
+
+// ParseOpeningHours reads the common subset of the OpenStreetMap opening_hours
+// syntax, e.g. "Mo-Fr 12:00-15:00,19:00-23:00; Sa 12:00-01:00; Su off" or "24/7"
+func ParseOpeningHours(value string) (*OpeningHours, error) {
+	value = strings.TrimSpace(value)
+	if value == "24/7" {
+		return &OpeningHours{Periods: []Period{{Open: DayTime{Day: time.Sunday, Time: "0000"}}}}, nil
+	}
+
+	week := make(map[time.Weekday][]span)
+	for _, rule := range strings.Split(value, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		days, times := allDays(), rule
+		if fields := strings.SplitN(rule, " ", 2); len(fields) == 2 && !strings.Contains(fields[0], ":") {
+			var err error
+			if days, err = parseOSMDays(fields[0]); err != nil {
+				return nil, err
+			}
+			times = strings.TrimSpace(fields[1])
+		}
+
+		spans, err := parseOSMTimes(times)
+		if err != nil {
+			return nil, err
+		}
+		// Later rules replace earlier ones for the same days
+		for _, day := range days {
+			week[day] = spans
+		}
+	}
+
+	hours := &OpeningHours{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		for _, s := range week[day] {
+			closeDay := day
+			closeAt := s.close
+			if closeAt <= s.open {
+				closeDay = (day + 1) % 7
+			}
+			if closeAt == minutesPerDay {
+				closeDay, closeAt = (day+1)%7, 0
+			}
+
+			hours.Periods = append(hours.Periods, Period{
+				Open:  DayTime{Day: day, Time: hhmm(s.open)},
+				Close: &DayTime{Day: closeDay, Time: hhmm(closeAt)},
+			})
+		}
+	}
+
+	if len(hours.Periods) == 0 && !strings.Contains(value, "off") {
+		return nil, ErrInvalidOpeningHours
+	}
+
+	return hours, nil
+}
+
+func allDays() []time.Weekday {
+	return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+}
+
+func parseOSMDays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, ok := osmDays[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown day %q", ErrInvalidOpeningHours.Error(), bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = osmDays[bounds[1]]; !ok {
+				return nil, fmt.Errorf("%s: unknown day %q", ErrInvalidOpeningHours.Error(), bounds[1])
+			}
+		}
+
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseOSMTimes(value string) ([]span, error) {
+	if value == "off" || value == "closed" {
+		return nil, nil
+	}
+
+	var spans []span
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		if len(bounds) != 2 {
+			return nil, ErrInvalidOpeningHours
+		}
+		open, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		close, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span{open: open, close: close})
+	}
+	return spans, nil
+}
+
+func parseClock(value string) (int, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return 0, ErrInvalidOpeningHours
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, ErrInvalidOpeningHours
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes > 0) {
+		return 0, ErrInvalidOpeningHours
+	}
+	return hours*60 + minutes, nil
+}
+
+func hhmm(minutes int) string {
+	return fmt.Sprintf("%02d%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,94 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIsOpenAt(t *testing.T) {
+	// 2019-03-15 is a Friday
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2019, 3, 15, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		desc     string
+		hours    string
+		at       time.Time
+		expected bool
+	}{
+		{
+			desc:     "Open for lunch",
+			hours:    "Mo-Fr 12:00-15:00,19:00-23:00",
+			at:       friday(13, 30),
+			expected: true,
+		},
+		{
+			desc:     "Closed between services",
+			hours:    "Mo-Fr 12:00-15:00,19:00-23:00",
+			at:       friday(16, 0),
+			expected: false,
+		},
+		{
+			desc:     "Closing time is exclusive",
+			hours:    "Mo-Fr 12:00-15:00",
+			at:       friday(15, 0),
+			expected: false,
+		},
+		{
+			desc:     "Overnight span still open after midnight",
+			hours:    "Fr 22:00-02:00",
+			at:       friday(24, 30),
+			expected: true,
+		},
+		{
+			desc:     "Saturday night spans into Sunday across the week boundary",
+			hours:    "Sa 20:00-03:00",
+			at:       time.Date(2019, 3, 17, 1, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			desc:     "Later rules override earlier ones",
+			hours:    "Mo-Fr 12:00-23:00; Fr off",
+			at:       friday(13, 0),
+			expected: false,
+		},
+		{
+			desc:     "Always open",
+			hours:    "24/7",
+			at:       friday(4, 0),
+			expected: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			hours, err := ParseOpeningHours(tC.hours)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hours.IsOpenAt(tC.at)).To(Equal(tC.expected))
+		})
+	}
+}
+
+func TestIsOpenAtTimeZone(t *testing.T) {
+	RegisterTestingT(t)
+	hours, err := ParseOpeningHours("Mo-Su 12:00-15:00")
+	Expect(err).NotTo(HaveOccurred())
+	tokyo := 9 * 60
+	hours.UTCOffset = &tokyo
+
+	// 04:00 UTC is 13:00 in Tokyo
+	Expect(hours.IsOpenAt(time.Date(2019, 3, 15, 4, 0, 0, 0, time.UTC))).To(BeTrue())
+	Expect(hours.IsOpenAt(time.Date(2019, 3, 15, 13, 0, 0, 0, time.UTC))).To(BeFalse())
+}
+
+func TestParseOpeningHoursErrors(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, value := range []string{"", "sunrise-sunset", "Xx 10:00-12:00", "Mo 10-12", "Mo 25:00-26:00"} {
+		_, err := ParseOpeningHours(value)
+		Expect(err).To(HaveOccurred(), value)
+	}
+}
@@ -2,22 +2,95 @@ package entities
 
 import (
 	"encoding/json"
-	"strings"
 	"time"
-
-	"googlemaps.github.io/maps"
 )
 
 // Place ...
 type Place struct {
-	Address    string      `json:"address"`
-	Location   maps.LatLng `json:"location"`
-	Name       string      `json:"name"`
-	Phone      string      `json:"phone"`
-	Rating     float32     `json:"rating"`
-	Schedule   string      `json:"schedule"`
-	PriceLevel int         `json:"price_level"`
-	Types      string      `json:"types"`
+	ID         string          `json:"id"`
+	Address    string          `json:"address"`
+	Location   Location        `json:"location"`
+	Name       string          `json:"name"`
+	Phone      string          `json:"phone"`
+	Website    string          `json:"website,omitempty"`
+	Rating     float32         `json:"rating"`
+	Schedule   string          `json:"schedule"`
+	Hours      *OpeningHours   `json:"opening_hours,omitempty"`
+	OpenNow    *bool           `json:"open_now,omitempty"`
+	PriceLevel *int            `json:"price_level,omitempty"`
+	Cuisine    string          `json:"cuisine,omitempty"`
+	Types      string          `json:"types"`
+	Reviews    *ReviewsSummary `json:"reviews,omitempty"`
+	Photos     []string        `json:"photos,omitempty"`
+	Chosen     bool            `json:"chosen,omitempty"`
+}
+
+// ReviewsSummary a digest of the reviews a source returned for a place
+type ReviewsSummary struct {
+	Count   int     `json:"count"`
+	Rating  float32 `json:"rating"`
+	Excerpt string  `json:"excerpt,omitempty"`
+}
+
+// EnrichedWith returns the place completed with whatever details knows better,
+// such as the full address instead of the vicinity
+func (p Place) EnrichedWith(details Place) Place {
+	enriched := p
+
+	for _, field := range []struct {
+		target *string
+		value  string
+	}{
+		{&enriched.Address, details.Address},
+		{&enriched.Name, details.Name},
+		{&enriched.Phone, details.Phone},
+		{&enriched.Website, details.Website},
+		{&enriched.Schedule, details.Schedule},
+		{&enriched.Cuisine, details.Cuisine},
+		{&enriched.Types, details.Types},
+	} {
+		if field.value != "" {
+			*field.target = field.value
+		}
+	}
+
+	if details.Hours != nil {
+		enriched.Hours = details.Hours
+	}
+	if details.OpenNow != nil {
+		enriched.OpenNow = details.OpenNow
+	}
+	if details.PriceLevel != nil {
+		enriched.PriceLevel = details.PriceLevel
+	}
+	if details.Reviews != nil {
+		enriched.Reviews = details.Reviews
+	}
+	if len(details.Photos) > 0 {
+		enriched.Photos = details.Photos
+	}
+
+	return enriched
+}
+
+// IsOpenAt returns whether the place is open at the given instant, along with
+// whether that is actually known. Without weekly hours only "now" can be answered
+func (p Place) IsOpenAt(t time.Time, now bool) (open bool, known bool) {
+	if p.Hours != nil {
+		return p.Hours.IsOpenAt(t), true
+	}
+	if now && p.OpenNow != nil {
+		return *p.OpenNow, true
+	}
+	return false, false
+}
+
+// Places a ranked list of places
+type Places []Place
+
+// ToJSON returns a JSON representation of Places
+func (p Places) ToJSON() (json.RawMessage, error) {
+	return json.Marshal(p)
 }
 
 // Jsonable an entity that returns a JSON representation of itself
@@ -29,27 +102,3 @@ type Jsonable interface {
 func (p Place) ToJSON() (json.RawMessage, error) {
 	return json.Marshal(p)
 }
-
-// PlaceFrom ...
-func PlaceFrom(details maps.PlacesSearchResult) Place {
-	place := Place{}
-	place.Address = details.Vicinity // Hack because of nearbySearchRequest, should be details.FormattedAddress
-	place.Location = details.Geometry.Location
-	place.Name = details.Name
-	place.PriceLevel = details.PriceLevel
-	place.Rating = details.Rating
-	// FIXME: Make this safe
-	place.Schedule = stringFrom(details.OpeningHours)
-	place.Types = strings.Join(details.Types, ",")
-
-	return place
-}
-
-func stringFrom(schedule *maps.OpeningHours) string {
-	for _, p := range schedule.Periods {
-		if time.Now().Weekday() == p.Open.Day {
-			return p.Open.Time
-		}
-	}
-	return ""
-}
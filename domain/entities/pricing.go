@@ -0,0 +1,177 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price levels as reported by the places sources, from free to very expensive
+const (
+	MinPriceLevel = 0
+	MaxPriceLevel = 4
+
+	anyPricing = "any"
+)
+
+// ErrInvalidPricing error sent when a pricing can't be understood
+var ErrInvalidPricing error = NewError(Validation, `pricing must be "any", a level or a "min-max" range between 0 and 4`)
+
+// Pricing the accepted price levels of a search, the zero value accepts any price
+type Pricing struct {
+	min     int
+	max     int
+	limited bool
+}
+
+// KnownPrice returns a place price level, which is nil when the source doesn't know it
+func KnownPrice(level int) *int {
+	return &level
+}
+
+// AnyPrice returns a pricing accepting every price level
+func AnyPrice() Pricing {
+	return Pricing{}
+}
+
+// ExactPrice returns a pricing accepting a single price level
+func ExactPrice(level int) Pricing {
+	return PriceRange(level, level)
+}
+
+// PriceRange returns a pricing accepting levels from min to max, inclusive
+func PriceRange(min, max int) Pricing {
+	return Pricing{min: min, max: max, limited: true}
+}
+
+// ParsePricing reads "any", a single level such as "1" or a range such as "1-2".
+// A single 0 is any price, as searches sent it before pricing could be a
+// range, free places only being "0-0"
+func ParsePricing(value string) (Pricing, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, anyPricing) {
+		return AnyPrice(), nil
+	}
+
+	bounds := strings.SplitN(value, "-", 2)
+	min, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return Pricing{}, ErrInvalidPricing
+	}
+	max := min
+	if len(bounds) == 2 {
+		if max, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+			return Pricing{}, ErrInvalidPricing
+		}
+	} else if min == 0 {
+		return AnyPrice(), nil
+	}
+
+	pricing := PriceRange(min, max)
+	return pricing, pricing.Validate()
+}
+
+// IsAny returns whether every price level is accepted
+func (p Pricing) IsAny() bool {
+	return !p.limited
+}
+
+// Bounds returns the lowest and highest accepted price levels
+func (p Pricing) Bounds() (min, max int) {
+	if p.IsAny() {
+		return MinPriceLevel, MaxPriceLevel
+	}
+	return p.min, p.max
+}
+
+// Matches returns whether a price level is accepted, an unknown level can't be
+// ruled out and is always accepted
+func (p Pricing) Matches(level *int) bool {
+	if level == nil {
+		return true
+	}
+	min, max := p.Bounds()
+	return *level >= min && *level <= max
+}
+
+// Validate checks the levels are known and in order
+func (p Pricing) Validate() error {
+	if p.IsAny() {
+		return nil
+	}
+	if p.min < MinPriceLevel || p.max > MaxPriceLevel || p.min > p.max {
+		return ErrInvalidPricing
+	}
+	return nil
+}
+
+// String returns the pricing in the form accepted by ParsePricing
+func (p Pricing) String() string {
+	switch {
+	case p.IsAny():
+		return anyPricing
+	case p.min == p.max && p.min != 0:
+		return strconv.Itoa(p.min)
+	}
+	return fmt.Sprintf("%d-%d", p.min, p.max)
+}
+
+type pricingRange struct {
+	Min *int `json:"min"`
+	Max *int `json:"max"`
+}
+
+// UnmarshalJSON accepts a level (2, 0 being any price), a string read by
+// ParsePricing ("any", "1-2") or a range ({"min": 1, "max": 2})
+func (p *Pricing) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*p = AnyPrice()
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		pricing, err := ParsePricing(value)
+		if err != nil {
+			return err
+		}
+		*p = pricing
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		var bounds pricingRange
+		if err := json.Unmarshal(data, &bounds); err != nil {
+			return err
+		}
+		min, max := MinPriceLevel, MaxPriceLevel
+		if bounds.Min != nil {
+			min = *bounds.Min
+		}
+		if bounds.Max != nil {
+			max = *bounds.Max
+		}
+		*p = PriceRange(min, max)
+		return nil
+	}
+
+	var level int
+	if err := json.Unmarshal(data, &level); err != nil {
+		return ErrInvalidPricing
+	}
+	pricing, err := ParsePricing(strconv.Itoa(level))
+	if err != nil {
+		return err
+	}
+	*p = pricing
+	return nil
+}
+
+// MarshalJSON writes the pricing as accepted by ParsePricing
+func (p Pricing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
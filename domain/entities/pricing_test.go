@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPricingFromJSON(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		body        string
+		expected    Pricing
+		expectError bool
+	}{
+		{
+			desc:     "Missing pricing",
+			body:     `{"lat": 38.71, "lng": -9.13, "distance": 500}`,
+			expected: AnyPrice(),
+		},
+		{
+			desc:     "Explicit any",
+			body:     `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": "any"}`,
+			expected: AnyPrice(),
+		},
+		{
+			desc:     "Exact level",
+			body:     `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": 1}`,
+			expected: ExactPrice(1),
+		},
+		{
+			desc:     "Legacy zero",
+			body:     `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": 0}`,
+			expected: AnyPrice(),
+		},
+		{
+			desc:     "Range as a string",
+			body:     `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": "1-2"}`,
+			expected: PriceRange(1, 2),
+		},
+		{
+			desc:     "Range with only a max",
+			body:     `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": {"max": 1}}`,
+			expected: PriceRange(0, 1),
+		},
+		{
+			desc:        "Unknown word",
+			body:        `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": "cheap"}`,
+			expectError: true,
+		},
+		{
+			desc:        "Level out of bounds",
+			body:        `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": "3-7"}`,
+			expectError: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			sr, err := NewFromJSON([]byte(tC.body))
+			if tC.expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sr.Pricing).To(Equal(tC.expected))
+		})
+	}
+}
+
+func TestPricingValidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(PriceRange(0, 4).Validate()).To(Succeed())
+	Expect(PriceRange(3, 1).Validate()).To(MatchError(ErrInvalidPricing))
+	Expect(ExactPrice(5).Validate()).To(MatchError(ErrInvalidPricing))
+	Expect(PriceRange(1, 2).Matches(KnownPrice(2))).To(BeTrue())
+	Expect(PriceRange(1, 2).Matches(KnownPrice(3))).To(BeFalse())
+	Expect(PriceRange(1, 2).Matches(nil)).To(BeTrue(), "an unknown price can't be ruled out")
+	Expect(AnyPrice().Matches(KnownPrice(4))).To(BeTrue())
+}
+
+func TestPricingZeroMeansTheSameEverywhere(t *testing.T) {
+	RegisterTestingT(t)
+	const search = `{"lat": 38.71, "lng": -9.13, "distance": 500, "pricing": %s}`
+
+	for _, pricing := range []string{`0`, `"0"`} {
+		sr, err := NewFromJSON([]byte(fmt.Sprintf(search, pricing)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sr.Pricing).To(Equal(AnyPrice()), "POST pricing %s", pricing)
+	}
+	query, _ := url.ParseQuery("lat=38.71&lng=-9.13&distance=500&pricing=0")
+	sr, err := NewFromQuery(query)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(sr.Pricing).To(Equal(AnyPrice()), "GET pricing=0")
+
+	free := ExactPrice(0)
+	Expect(free.String()).To(Equal("0-0"), "free places only must not read back as any price")
+	sr, err = NewFromJSON([]byte(fmt.Sprintf(search, `"0-0"`)))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(sr.Pricing).To(Equal(free))
+	query, _ = url.ParseQuery("lat=38.71&lng=-9.13&distance=500&pricing=0-0")
+	sr, err = NewFromQuery(query)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(sr.Pricing).To(Equal(free))
+}
@@ -0,0 +1,163 @@
+package entities
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// zoneBox a rough bounding box of an IANA time zone. Boxes are checked in order,
+// so a box sits before the larger ones it is carved out of, and a zone whose
+// borders aren't straight takes several boxes. Places within a few kilometres
+// of a border may still get the neighbour's zone
+type zoneBox struct {
+	name                     string
+	south, west, north, east float64
+}
+
+var zoneBoxes = []zoneBox{
+	{"Atlantic/Azores", 36.8, -31.5, 39.8, -24.8},
+	{"Atlantic/Madeira", 32.3, -17.4, 33.2, -16.2},
+	{"Atlantic/Canary", 27.5, -18.3, 29.5, -13.3},
+	// Portugal, following the border with Spain southwards
+	{"Europe/Lisbon", 41.0, -9.6, 41.85, -6.45},
+	{"Europe/Lisbon", 40.4, -9.6, 41.0, -6.85},
+	{"Europe/Lisbon", 39.6, -9.6, 40.4, -6.95},
+	{"Europe/Lisbon", 39.2, -9.6, 39.6, -7.3},
+	{"Europe/Lisbon", 38.2, -9.6, 39.2, -7.15},
+	{"Europe/Lisbon", 36.9, -9.6, 38.2, -7.3},
+	{"Europe/Madrid", 35.9, -9.4, 43.8, 4.4},
+	{"Europe/Dublin", 51.4, -10.7, 55.4, -5.9},
+	{"Europe/London", 49.8, -8.7, 60.9, 1.8},
+	{"Europe/Brussels", 49.5, 2.55, 51.5, 6.4},
+	{"Europe/Amsterdam", 51.5, 3.3, 53.6, 7.3},
+	{"Europe/Zurich", 45.8, 5.9, 47.9, 10.5},
+	{"Europe/Paris", 42.3, -5.2, 51.1, 8.3},
+	{"Africa/Tunis", 30.2, 7.5, 37.6, 11.6},
+	{"Europe/Rome", 36.6, 6.6, 47.1, 18.6},
+	{"Europe/Berlin", 47.2, 5.8, 55.1, 15.1},
+	{"Europe/Kaliningrad", 54.45, 19.6, 55.3, 22.9},
+	{"Europe/Warsaw", 49.0, 14.1, 54.45, 23.65},
+	{"Europe/Vilnius", 53.9, 20.9, 56.45, 26.8},
+	{"Europe/Riga", 55.65, 20.9, 58.1, 28.25},
+	{"Europe/Tallinn", 58.1, 21.7, 59.7, 28.2},
+	{"Europe/Helsinki", 59.7, 19.3, 61.0, 27.9},
+	{"Europe/Helsinki", 61.0, 19.3, 63.5, 31.6},
+	{"Europe/Helsinki", 63.5, 24.15, 70.1, 31.6},
+	{"Europe/Stockholm", 55.3, 10.9, 69.1, 24.2},
+	{"Europe/Minsk", 51.9, 23.65, 56.2, 32.8},
+	// Ukraine, leaving out Russia east of Kharkiv and around Rostov
+	{"Europe/Kiev", 44.3, 22.1, 52.4, 35.0},
+	{"Europe/Kiev", 47.3, 35.0, 50.3, 40.2},
+	{"Europe/Kiev", 44.3, 35.0, 47.3, 38.2},
+	{"Europe/Moscow", 44.0, 27.5, 70.0, 48.0},
+	// Albania and North Macedonia, then Turkey around the Greek islands
+	{"Europe/Tirane", 40.2, 19.2, 42.7, 21.1},
+	{"Europe/Tirane", 39.65, 19.2, 40.2, 20.3},
+	{"Europe/Skopje", 40.85, 21.1, 42.4, 22.0},
+	{"Europe/Skopje", 41.1, 22.0, 42.1, 23.05},
+	{"Europe/Istanbul", 40.9, 26.6, 41.95, 41.4},
+	{"Europe/Istanbul", 41.95, 29.0, 42.15, 41.4},
+	{"Europe/Istanbul", 38.0, 26.7, 40.9, 43.5},
+	{"Europe/Istanbul", 36.6, 27.35, 38.0, 36.6},
+	{"Europe/Istanbul", 36.9, 36.6, 38.0, 40.0},
+	{"Europe/Istanbul", 37.2, 40.0, 38.0, 44.5},
+	{"Europe/Istanbul", 35.8, 29.5, 36.6, 36.5},
+	{"Europe/Athens", 34.8, 19.3, 41.8, 28.3},
+	{"America/Sao_Paulo", -33.8, -53.1, -14.0, -34.8},
+	// Western Canada, the Peace River country and the Kootenays keeping
+	// mountain time, before the border with the United States
+	{"America/Dawson_Creek", 55.0, -123.0, 60.0, -120.0},
+	{"America/Vancouver", 48.3, -125.0, 49.0, -123.2},
+	{"America/Vancouver", 49.0, -131.2, 54.7, -120.0},
+	{"America/Vancouver", 54.7, -130.0, 60.0, -120.0},
+	{"America/Vancouver", 49.0, -120.0, 52.0, -117.0},
+	{"America/Vancouver", 52.0, -120.0, 54.0, -118.7},
+	{"America/Edmonton", 49.0, -120.0, 60.0, -110.0},
+	{"America/Regina", 49.0, -110.0, 60.0, -101.4},
+	{"America/Winnipeg", 49.0, -101.4, 60.0, -89.0},
+	// Mexico, from Baja California down to Chiapas, leaving out Guatemala and
+	// the border towns keeping United States time
+	{"America/Tijuana", 28.0, -117.2, 32.53, -114.8},
+	{"America/Tijuana", 32.53, -115.9, 32.66, -114.8},
+	{"America/Hermosillo", 28.0, -114.8, 31.3, -108.4},
+	{"America/Hermosillo", 26.3, -111.3, 28.0, -108.4},
+	{"America/Mazatlan", 22.8, -115.2, 28.0, -109.4},
+	{"America/Mazatlan", 22.5, -109.5, 26.5, -105.5},
+	{"America/Mazatlan", 21.0, -105.8, 22.5, -104.6},
+	{"America/Chihuahua", 25.5, -108.4, 31.3, -104.1},
+	{"America/Cancun", 18.45, -89.2, 19.7, -87.3},
+	{"America/Cancun", 19.7, -88.0, 21.7, -86.7},
+	{"America/Mexico_City", 17.8, -105.7, 25.8, -86.7},
+	{"America/Mexico_City", 14.5, -105.7, 17.8, -92.2},
+	{"America/New_York", 24.5, -85.0, 47.5, -66.9},
+	{"America/Chicago", 25.8, -104.1, 49.4, -85.0},
+	// Arizona keeps standard time all year
+	{"America/Phoenix", 31.3, -114.82, 37.0, -109.05},
+	{"America/Denver", 31.3, -114.1, 49.0, -102.0},
+	{"America/Los_Angeles", 32.5, -124.8, 49.0, -114.1},
+	// Japan, around Korea and the Russian Far East
+	{"Asia/Tokyo", 24.0, 122.9, 30.9, 132.0},
+	{"Asia/Tokyo", 30.9, 129.3, 34.7, 132.0},
+	{"Asia/Tokyo", 33.0, 132.0, 41.6, 146.0},
+	{"Asia/Tokyo", 41.3, 139.3, 45.6, 146.0},
+	{"Asia/Seoul", 33.0, 124.5, 38.7, 131.0},
+	{"Asia/Pyongyang", 38.7, 124.5, 40.0, 128.4},
+	{"Asia/Pyongyang", 40.0, 125.0, 41.0, 129.8},
+	{"Asia/Pyongyang", 41.0, 128.3, 42.35, 130.7},
+	{"Asia/Vladivostok", 42.3, 131.2, 44.9, 140.0},
+	{"Asia/Vladivostok", 44.9, 133.1, 48.3, 140.0},
+	{"Asia/Vladivostok", 48.3, 134.8, 60.0, 141.0},
+	// Afghanistan and Pakistan, following the border with India northwards
+	{"Asia/Kabul", 33.9, 60.5, 38.5, 70.0},
+	{"Asia/Kabul", 29.4, 61.0, 33.9, 66.3},
+	{"Asia/Karachi", 23.6, 61.0, 28.0, 70.3},
+	{"Asia/Karachi", 28.0, 61.0, 30.5, 73.3},
+	{"Asia/Karachi", 30.5, 61.0, 31.2, 74.0},
+	{"Asia/Karachi", 31.2, 61.0, 37.1, 74.6},
+	// Nepal, from west to east
+	{"Asia/Kathmandu", 28.5, 80.05, 30.45, 81.5},
+	{"Asia/Kathmandu", 28.0, 81.5, 30.0, 83.0},
+	{"Asia/Kathmandu", 27.55, 83.0, 28.5, 84.0},
+	{"Asia/Kathmandu", 27.05, 84.0, 28.3, 85.9},
+	{"Asia/Kathmandu", 26.4, 85.9, 27.9, 88.15},
+	{"Asia/Thimphu", 26.7, 88.75, 28.35, 92.13},
+	// Bangladesh, leaving out West Bengal, Tripura and Meghalaya
+	{"Asia/Dhaka", 21.0, 89.0, 24.1, 91.2},
+	{"Asia/Dhaka", 24.1, 88.5, 25.2, 90.9},
+	{"Asia/Dhaka", 25.2, 88.7, 26.0, 89.9},
+	{"Asia/Dhaka", 24.2, 90.9, 25.2, 92.2},
+	{"Asia/Dhaka", 20.6, 91.35, 22.95, 92.7},
+	{"Asia/Yangon", 18.0, 92.2, 21.8, 94.5},
+	{"Asia/Yangon", 9.5, 93.0, 20.0, 98.6},
+	{"Asia/Yangon", 20.0, 92.2, 21.8, 101.2},
+	{"Asia/Yangon", 21.8, 94.2, 26.0, 97.6},
+	{"Asia/Shanghai", 29.3, 78.5, 53.6, 135.1},
+	{"Asia/Kolkata", 6.7, 68.1, 35.7, 97.4},
+	{"Australia/Sydney", -37.6, 140.9, -28.1, 153.7},
+}
+
+// TimeZoneAt returns the time zone at the given coordinates from an offline table
+// of rough zone boundaries, falling back to the nautical zone of the longitude
+func TimeZoneAt(lat, lng float64) *time.Location {
+	for _, box := range zoneBoxes {
+		if lat < box.south || lat > box.north || lng < box.west || lng > box.east {
+			continue
+		}
+		if location, err := time.LoadLocation(box.name); err == nil {
+			return location
+		}
+		break
+	}
+
+	return nauticalZone(lng)
+}
+
+// nauticalZone the fixed offset zone, one hour every 15 degrees of longitude
+func nauticalZone(lng float64) *time.Location {
+	offset := int(math.Round(lng / 15))
+	if offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%+d", offset), offset*3600)
+}
@@ -0,0 +1,159 @@
+package entities
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestZone(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		request     SearchRequest
+		expected    string
+		expectError bool
+	}{
+		{
+			desc:     "Explicit IANA time zone wins",
+			request:  SearchRequest{Lat: 38.7107, Lng: -9.1365, TimeZone: "America/New_York"},
+			expected: "America/New_York",
+		},
+		{
+			desc:     "Derived from coordinates in Lisbon",
+			request:  SearchRequest{Lat: 38.7107, Lng: -9.1365},
+			expected: "Europe/Lisbon",
+		},
+		{
+			desc:     "Derived from coordinates in the Azores",
+			request:  SearchRequest{Lat: 37.7412, Lng: -25.6756},
+			expected: "Atlantic/Azores",
+		},
+		{
+			desc:     "Nautical zone in the middle of the Pacific",
+			request:  SearchRequest{Lat: -10, Lng: -150},
+			expected: "UTC-10",
+		},
+		{
+			desc:        "Unknown time zone",
+			request:     SearchRequest{TimeZone: "Europe/Atlantis"},
+			expectError: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			zone, err := tC.request.Zone()
+			if tC.expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zone.String()).To(Equal(tC.expected))
+		})
+	}
+}
+
+func TestTimeZoneAtBorders(t *testing.T) {
+	testCases := []struct {
+		city     string
+		lat, lng float64
+		expected string
+	}{
+		{"Lisbon", 38.7223, -9.1393, "Europe/Lisbon"},
+		{"Elvas", 38.8810, -7.1628, "Europe/Lisbon"},
+		{"Badajoz", 38.8794, -6.9707, "Europe/Madrid"},
+		{"Faro", 37.0194, -7.9304, "Europe/Lisbon"},
+		{"Huelva", 37.2614, -6.9447, "Europe/Madrid"},
+		{"Bragança", 41.8061, -6.7567, "Europe/Lisbon"},
+		{"Vigo", 42.2406, -8.7207, "Europe/Madrid"},
+		{"Paris", 48.8566, 2.3522, "Europe/Paris"},
+		{"Brussels", 50.8503, 4.3517, "Europe/Brussels"},
+		{"Riga", 56.9496, 24.1052, "Europe/Riga"},
+		{"Stockholm", 59.3293, 18.0686, "Europe/Stockholm"},
+		{"Helsinki", 60.1699, 24.9384, "Europe/Helsinki"},
+		{"Minsk", 53.9006, 27.5590, "Europe/Minsk"},
+		{"Kharkiv", 49.9935, 36.2304, "Europe/Kiev"},
+		{"Belgorod", 50.5997, 36.5983, "Europe/Moscow"},
+		{"Rostov-on-Don", 47.2357, 39.7015, "Europe/Moscow"},
+		{"Istanbul", 41.0082, 28.9784, "Europe/Istanbul"},
+		{"Izmir", 38.4237, 27.1428, "Europe/Istanbul"},
+		{"Bodrum", 37.0344, 27.4305, "Europe/Istanbul"},
+		{"Athens", 37.9838, 23.7275, "Europe/Athens"},
+		{"Rhodes", 36.4341, 28.2176, "Europe/Athens"},
+		{"Mytilene", 39.1044, 26.5546, "Europe/Athens"},
+		{"Thessaloniki", 40.6401, 22.9444, "Europe/Athens"},
+		{"Tirana", 41.3275, 19.8187, "Europe/Tirane"},
+		{"Phoenix", 33.4484, -112.0740, "America/Phoenix"},
+		{"Yuma", 32.6927, -114.6277, "America/Phoenix"},
+		{"Las Vegas", 36.1699, -115.1398, "America/Los_Angeles"},
+		{"Denver", 39.7392, -104.9903, "America/Denver"},
+		{"Albuquerque", 35.0844, -106.6504, "America/Denver"},
+		{"Seattle", 47.6062, -122.3321, "America/Los_Angeles"},
+		{"Bellingham", 48.7519, -122.4787, "America/Los_Angeles"},
+		{"Victoria", 48.4284, -123.3656, "America/Vancouver"},
+		{"Vancouver", 49.2827, -123.1207, "America/Vancouver"},
+		{"Kelowna", 49.8880, -119.4960, "America/Vancouver"},
+		{"Nelson", 49.4928, -117.2948, "America/Vancouver"},
+		{"Prince George", 53.9171, -122.7497, "America/Vancouver"},
+		{"Dawson Creek", 55.7596, -120.2353, "America/Dawson_Creek"},
+		{"Golden", 51.2976, -116.9631, "America/Edmonton"},
+		{"Cranbrook", 49.5122, -115.7694, "America/Edmonton"},
+		{"Calgary", 51.0447, -114.0719, "America/Edmonton"},
+		{"Edmonton", 53.5461, -113.4938, "America/Edmonton"},
+		{"Regina", 50.4452, -104.6189, "America/Regina"},
+		{"Saskatoon", 52.1332, -106.6700, "America/Regina"},
+		{"Winnipeg", 49.8951, -97.1384, "America/Winnipeg"},
+		{"San Diego", 32.7157, -117.1611, "America/Los_Angeles"},
+		{"Tijuana", 32.5149, -117.0382, "America/Tijuana"},
+		{"Mexicali", 32.6245, -115.4523, "America/Tijuana"},
+		{"Ensenada", 31.8667, -116.5964, "America/Tijuana"},
+		{"Hermosillo", 29.0729, -110.9559, "America/Hermosillo"},
+		{"Guaymas", 27.9179, -110.8989, "America/Hermosillo"},
+		{"Santa Rosalía", 27.3389, -112.2666, "America/Mazatlan"},
+		{"La Paz", 24.1426, -110.3128, "America/Mazatlan"},
+		{"Culiacán", 24.8091, -107.3940, "America/Mazatlan"},
+		{"Tepic", 21.5042, -104.8946, "America/Mazatlan"},
+		{"Puerto Vallarta", 20.6534, -105.2253, "America/Mexico_City"},
+		{"Chihuahua", 28.6320, -106.0691, "America/Chihuahua"},
+		{"El Paso", 31.7619, -106.4850, "America/Denver"},
+		{"Monterrey", 25.6866, -100.3161, "America/Mexico_City"},
+		{"Houston", 29.7604, -95.3698, "America/Chicago"},
+		{"Guadalajara", 20.6597, -103.3496, "America/Mexico_City"},
+		{"Mexico City", 19.4326, -99.1332, "America/Mexico_City"},
+		{"Tuxtla Gutiérrez", 16.7516, -93.1029, "America/Mexico_City"},
+		{"Mérida", 20.9674, -89.5926, "America/Mexico_City"},
+		{"Valladolid", 20.6896, -88.2022, "America/Mexico_City"},
+		{"Cancún", 21.1619, -86.8515, "America/Cancun"},
+		{"Chetumal", 18.5001, -88.2961, "America/Cancun"},
+		{"Guatemala City", 14.6349, -90.5069, "UTC-6"},
+		{"Lahore", 31.5204, 74.3587, "Asia/Karachi"},
+		{"Amritsar", 31.6340, 74.8723, "Asia/Kolkata"},
+		{"Karachi", 24.8607, 67.0011, "Asia/Karachi"},
+		{"Kabul", 34.5553, 69.2075, "Asia/Kabul"},
+		{"Kathmandu", 27.7172, 85.3240, "Asia/Kathmandu"},
+		{"Pokhara", 28.2096, 83.9856, "Asia/Kathmandu"},
+		{"Lucknow", 26.8467, 80.9462, "Asia/Kolkata"},
+		{"Darjeeling", 27.0410, 88.2663, "Asia/Kolkata"},
+		{"Dhaka", 23.8103, 90.4125, "Asia/Dhaka"},
+		{"Chittagong", 22.3569, 91.7832, "Asia/Dhaka"},
+		{"Kolkata", 22.5726, 88.3639, "Asia/Kolkata"},
+		{"Agartala", 23.8315, 91.2868, "Asia/Kolkata"},
+		{"Yangon", 16.8409, 96.1735, "Asia/Yangon"},
+		{"Lhasa", 29.6520, 91.1721, "Asia/Shanghai"},
+		{"Shenyang", 41.8057, 123.4315, "Asia/Shanghai"},
+		{"Seoul", 37.5665, 126.9780, "Asia/Seoul"},
+		{"Busan", 35.1796, 129.0756, "Asia/Seoul"},
+		{"Fukuoka", 33.5904, 130.4017, "Asia/Tokyo"},
+		{"Tokyo", 35.6762, 139.6503, "Asia/Tokyo"},
+		{"Sapporo", 43.0618, 141.3545, "Asia/Tokyo"},
+		{"Vladivostok", 43.1198, 131.8869, "Asia/Vladivostok"},
+		{"Khabarovsk", 48.4802, 135.0719, "Asia/Vladivostok"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.city, func(t *testing.T) {
+			RegisterTestingT(t)
+			Expect(TimeZoneAt(tC.lat, tC.lng).String()).To(Equal(tC.expected))
+		})
+	}
+}
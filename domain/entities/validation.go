@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Search radius bounds in meters, Google doesn't search further than 50km
+const (
+	MinDistance = 1
+	MaxDistance = 50000
+)
+
+// Strategies the selection strategies a search can name, none picking the default
+var Strategies = []string{"uniform", "rating", "distance", "softmax"}
+
+// FieldError a problem with a single field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+}
+
+// ValidationError lists every problem found in a request
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (v ValidationError) Error() string {
+	problems := make([]string, 0, len(v.Fields))
+	for _, field := range v.Fields {
+		problems = append(problems, field.Field+" "+field.Problem)
+	}
+	return "invalid request: " + strings.Join(problems, "; ")
+}
+
+// ErrorKind returns Validation
+func (v ValidationError) ErrorKind() ErrorKind {
+	return Validation
+}
+
+// violations collects the field errors of a request, one per field
+type violations struct {
+	fields []FieldError
+	seen   map[string]bool
+}
+
+func (v *violations) add(field, problem string) {
+	if v.seen == nil {
+		v.seen = make(map[string]bool)
+	}
+	if v.seen[field] {
+		return
+	}
+	v.seen[field] = true
+	v.fields = append(v.fields, FieldError{Field: field, Problem: problem})
+}
+
+func (v *violations) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return ValidationError{Fields: v.fields}
+}
+
+// Validate checks the search can be made, reporting every problem at once
+func (sr SearchRequest) Validate() error {
+	var v violations
+	sr.validate(&v)
+	return v.err()
+}
+
+func (sr SearchRequest) validate(v *violations) {
+	if sr.Lat < -90 || sr.Lat > 90 {
+		v.add("lat", "must be between -90 and 90")
+	}
+	if sr.Lng < -180 || sr.Lng > 180 {
+		v.add("lng", "must be between -180 and 180")
+	}
+	if sr.Distance < MinDistance || sr.Distance > MaxDistance {
+		v.add("distance", fmt.Sprintf("must be between %d and %d meters", MinDistance, MaxDistance))
+	}
+	if err := sr.Pricing.Validate(); err != nil {
+		v.add("pricing", err.Error())
+	}
+	if sr.Strategy != "" && !isStrategy(sr.Strategy) {
+		v.add("strategy", "must be one of "+strings.Join(Strategies, ", "))
+	}
+	if sr.Count > MaxShortlist {
+		v.add("count", fmt.Sprintf("must be at most %d", MaxShortlist))
+	}
+	if _, err := sr.Zone(); err != nil {
+		v.add("time_zone", "is not a known IANA time zone")
+	}
+}
+
+func isStrategy(name string) bool {
+	for _, strategy := range Strategies {
+		if name == strategy {
+			return true
+		}
+	}
+	return false
+}
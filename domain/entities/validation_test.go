@@ -0,0 +1,116 @@
+package entities
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewFromJSONValidation(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		body     string
+		expected []FieldError
+	}{
+		{
+			desc: "Valid search",
+			body: `{"lat": 38.7107, "lng": -9.1365, "distance": 500, "pricing": "1-2", "time_zone": "Europe/Lisbon"}`,
+		},
+		{
+			desc: "Equator and prime meridian are valid",
+			body: `{"lat": 0, "lng": 0, "distance": 500}`,
+		},
+		{
+			desc: "Missing coordinates",
+			body: `{"distance": 500}`,
+			expected: []FieldError{
+				{Field: "lat", Problem: "is required"},
+				{Field: "lng", Problem: "is required"},
+			},
+		},
+		{
+			desc: "Near instead of coordinates",
+			body: `{"near": "Rua Augusta, Lisboa", "distance": 500}`,
+		},
+		{
+			desc:     "Blank near",
+			body:     `{"near": " ", "distance": 500}`,
+			expected: []FieldError{{Field: "near", Problem: "must not be blank"}},
+		},
+		{
+			desc:     "Near along with coordinates",
+			body:     `{"near": "Rua Augusta, Lisboa", "lat": 38.7107, "distance": 500}`,
+			expected: []FieldError{{Field: "near", Problem: "must not be given along with lat and lng"}},
+		},
+		{
+			desc: "Every problem at once",
+			body: `{"lat": 91, "lng": -181, "distance": 2000000, "pricing": "3-7", "radius": 500, "count": 20, "strategy": "cheapest"}`,
+			expected: []FieldError{
+				{Field: "radius", Problem: "is not a known field"},
+				{Field: "pricing", Problem: ErrInvalidPricing.Error()},
+				{Field: "lat", Problem: "must be between -90 and 90"},
+				{Field: "lng", Problem: "must be between -180 and 180"},
+				{Field: "distance", Problem: "must be between 1 and 50000 meters"},
+				{Field: "strategy", Problem: "must be one of uniform, rating, distance, softmax"},
+				{Field: "count", Problem: "must be at most 10"},
+			},
+		},
+		{
+			desc: "Wrong types",
+			body: `{"lat": "38.71", "lng": -9.13, "distance": -5, "open_at": "lunch", "time_zone": "Mars/Olympus"}`,
+			expected: []FieldError{
+				{Field: "lat", Problem: "must be a number"},
+				{Field: "distance", Problem: "must be a non-negative whole number"},
+				{Field: "open_at", Problem: "must be an RFC 3339 time"},
+				{Field: "time_zone", Problem: "is not a known IANA time zone"},
+			},
+		},
+		{
+			desc:     "Not an object",
+			body:     `[38.71, -9.13]`,
+			expected: []FieldError{{Field: "body", Problem: "must be a JSON object"}},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			_, err := NewFromJSON([]byte(tC.body))
+			if tC.expected == nil {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+
+			Expect(err).To(BeAssignableToTypeOf(ValidationError{}))
+			Expect(err.(ValidationError).Fields).To(Equal(tC.expected))
+			Expect(KindOf(err)).To(Equal(Validation))
+		})
+	}
+}
+
+func TestNewFromQuery(t *testing.T) {
+	RegisterTestingT(t)
+
+	query, _ := url.ParseQuery("lat=38.7107&lng=-9.1365&distance=500&pricing=1-2&open_now=1&count=3&strategy=distance")
+	sr, err := NewFromQuery(query)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(sr).To(Equal(SearchRequest{
+		Lat:      38.7107,
+		Lng:      -9.1365,
+		Distance: 500,
+		Pricing:  PriceRange(1, 2),
+		Strategy: "distance",
+		Count:    3,
+		OpenNow:  true,
+	}))
+
+	query, _ = url.ParseQuery("lat=north&lng=-9.13&lng=-9.14&distance=-5&pricing=2&radius=1")
+	_, err = NewFromQuery(query)
+	Expect(err).To(BeAssignableToTypeOf(ValidationError{}))
+	Expect(err.(ValidationError).Fields).To(ConsistOf(
+		FieldError{Field: "lng", Problem: "must be given once"},
+		FieldError{Field: "radius", Problem: "is not a known field"},
+		FieldError{Field: "lat", Problem: "must be a number"},
+		FieldError{Field: "distance", Problem: "must be a non-negative whole number"},
+	))
+}
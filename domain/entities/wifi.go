@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessPoint a Wi-Fi access point seen by a scan
+type AccessPoint struct {
+	BSSID string `json:"bssid"`
+	SSID  string `json:"ssid,omitempty"`
+	// Channel the channel number, 0 when unknown
+	Channel int `json:"channel"`
+	// Frequency the center frequency in MHz, 0 when unknown
+	Frequency int `json:"frequency,omitempty"`
+	// SignalStrength the received signal in dBm, 0 when unknown
+	SignalStrength int `json:"signal_strength,omitempty"`
+	// Age how long ago the access point was last seen
+	Age time.Duration `json:"age,omitempty"`
+}
+
+// IsLocallyAdministered returns whether the BSSID was made up rather than
+// assigned by the manufacturer, as phone hotspots and randomized MACs are.
+// Such access points move around and only mislead geolocation
+func (a AccessPoint) IsLocallyAdministered() bool {
+	if len(a.BSSID) < 2 {
+		return false
+	}
+	firstOctet, err := strconv.ParseUint(a.BSSID[:2], 16, 8)
+	return err == nil && firstOctet&0x02 != 0
+}
+
+// OptsOut returns whether the owner asked for the access point not to be used
+// for locating, by ending its SSID with _nomap
+func (a AccessPoint) OptsOut() bool {
+	return strings.HasSuffix(a.SSID, "_nomap")
+}
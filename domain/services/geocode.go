@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// samePlaceRadius meters within which matches are taken for the same place,
+// such as the segments of a street
+const samePlaceRadius = 500
+
+// ErrNoGeocoder error sent when searching near an address without a geocoder configured
+var ErrNoGeocoder = errors.New("no geocoder configured")
+
+// Geocoder finds the places an address or place name refers to, best match first
+type Geocoder interface {
+	Forward(ctx context.Context, query string) ([]domain.Match, error)
+}
+
+// Geocode returns the single place query refers to, an AmbiguousError listing
+// the candidates when it could be several places apart
+func (l Locate) Geocode(ctx context.Context, query string) (domain.Match, error) {
+	return l.geocode(ctx, "query", query)
+}
+
+func (l Locate) geocode(ctx context.Context, field, query string) (domain.Match, error) {
+	if l.geocoder == nil {
+		return domain.Match{}, ErrNoGeocoder
+	}
+
+	matches, err := l.geocoder.Forward(ctx, query)
+	if err != nil {
+		return domain.Match{}, err
+	}
+
+	candidates := distinctPlaces(matches)
+	switch len(candidates) {
+	case 0:
+		return domain.Match{}, domain.NewError(domain.NotFound, fmt.Sprintf("no place matches %q", query))
+	case 1:
+		return candidates[0], nil
+	}
+	return domain.Match{}, domain.AmbiguousError{Field: field, Query: query, Candidates: candidates}
+}
+
+// resolveNear returns the search with its coordinates set to the place it's near
+func (l Locate) resolveNear(ctx context.Context, req domain.SearchRequest) (domain.SearchRequest, error) {
+	if req.Near == "" {
+		return req, nil
+	}
+
+	match, err := l.geocode(ctx, "near", req.Near)
+	if err != nil {
+		return req, err
+	}
+	req.Lat, req.Lng = match.Position.Location.Lat, match.Position.Location.Lng
+	return req, nil
+}
+
+// distinctPlaces drops the matches lying close to a better one
+func distinctPlaces(matches []domain.Match) []domain.Match {
+	var distinct []domain.Match
+	for _, match := range matches {
+		duplicate := false
+		for _, kept := range distinct {
+			if kept.Position.Location.DistanceTo(match.Position.Location) < samePlaceRadius {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			distinct = append(distinct, match)
+		}
+	}
+	return distinct
+}
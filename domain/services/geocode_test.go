@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	mocks "github.com/romeufcrosa/where-to-eat/tests/mocks/domain/services"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFetchRestaurantNear(t *testing.T) {
+	baixa := domain.Match{
+		Address:  "Rua Augusta, 1100-053 Lisboa",
+		Position: domain.Position{Location: domain.Location{Lat: 38.7107, Lng: -9.1365}},
+	}
+	baixaNorth := domain.Match{
+		Address:  "Rua Augusta, 1100-048 Lisboa",
+		Position: domain.Position{Location: domain.Location{Lat: 38.7120, Lng: -9.1370}},
+	}
+	amadora := domain.Match{
+		Address:  "Rua Augusta, 2610-158 Amadora",
+		Position: domain.Position{Location: domain.Location{Lat: 38.7526, Lng: -9.2289}},
+	}
+
+	testCases := []struct {
+		desc     string
+		matches  []domain.Match
+		expected error
+	}{
+		{
+			desc:    "Segments of the same street are one place",
+			matches: []domain.Match{baixa, baixaNorth},
+		},
+		{
+			desc:     "Places apart are ambiguous",
+			matches:  []domain.Match{baixa, baixaNorth, amadora},
+			expected: domain.AmbiguousError{Field: "near", Query: "Rua Augusta", Candidates: []domain.Match{baixa, amadora}},
+		},
+		{
+			desc:     "Nothing matches",
+			expected: domain.NewError(domain.NotFound, `no place matches "Rua Augusta"`),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			request := domain.SearchRequest{Near: "Rua Augusta", Distance: 500}
+			resolved := request
+			resolved.Lat, resolved.Lng = 38.7107, -9.1365
+
+			geocoder := &mocks.Geocoder{}
+			geocoder.On("Forward", mock.Anything, "Rua Augusta").Return(tC.matches, nil)
+			source := &mocks.PlacesSource{}
+			source.On("ListRestaurants", mock.Anything, resolved).Return([]domain.Place{{ID: "tasca", Rating: 4.2}}, nil)
+			source.On("PlaceDetails", mock.Anything, "tasca").Return(domain.Place{}, nil)
+
+			locator := NewGeolocatorWith(nil, source).WithGeocoder(geocoder)
+			place, err := locator.FetchRestaurant(context.Background(), request)
+			if tC.expected != nil {
+				Expect(err).To(Equal(tC.expected))
+				Expect(domain.KindOf(err)).NotTo(Equal(domain.Unknown))
+				source.AssertNotCalled(t, "ListRestaurants", mock.Anything, mock.Anything)
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(place.ID).To(Equal("tasca"))
+		})
+	}
+}
+
+func TestFetchRestaurantNearWithoutGeocoder(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewGeolocatorWith(nil, &mocks.PlacesSource{}).FetchRestaurant(context.Background(), domain.SearchRequest{Near: "Rua Augusta", Distance: 500})
+	Expect(err).To(MatchError(ErrNoGeocoder))
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"log"
-	"math/rand"
-	"strconv"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,113 +16,327 @@ import (
 // GeoLocator ...
 type GeoLocator interface {
 	Geolocate(ctx context.Context, accessPoints []maps.WiFiAccessPoint) (*maps.GeolocationResult, error)
-	ListRestaurants(ctx context.Context, searchRequest *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error)
-	PlaceDetails(ctx context.Context, detailsRequest *maps.PlaceDetailsRequest) (domain.Place, error)
 }
 
-// Wifi ...
-type Wifi struct {
-	BSSID   string
-	Channel int
-}
+// ErrNoGeoLocator error sent when locating without a geo locator configured
+var ErrNoGeoLocator = errors.New("no geo locator configured")
 
 // Locate ...
 type Locate struct {
-	google GeoLocator
+	geo      GeoLocator
+	places   PlacesSource
+	geocoder Geocoder
+	now      func() time.Time
 }
 
 // NewGeolocatorWith ...
-func NewGeolocatorWith(google GeoLocator) Locate {
+func NewGeolocatorWith(geo GeoLocator, places PlacesSource) Locate {
 	return Locate{
-		google: google,
+		geo:    geo,
+		places: places,
+		now:    time.Now,
 	}
 }
 
-// FetchLocation ...
-func (l Locate) FetchLocation(ctx context.Context, airportRows []string) (*maps.GeolocationResult, error) {
-	m := make(map[int]Wifi)
+// WithGeocoder returns the locator searching near addresses and place names with geocoder
+func (l Locate) WithGeocoder(geocoder Geocoder) Locate {
+	l.geocoder = geocoder
+	return l
+}
+
+// MaxAccessPoints the most access points sent to the geo locator, the strongest
+// ones being kept
+const MaxAccessPoints = 20
 
-	if (len(airportRows)) < 2 {
-		return nil, errors.New("no APs available")
+// ErrNotEnoughAccessPoints error sent when fewer than two access points are left
+// to locate from, the least Wi-Fi geolocation needs
+var ErrNotEnoughAccessPoints error = domain.NewError(domain.Validation, "at least 2 locatable Wi-Fi access points are needed")
+
+// FetchLocation returns the position of the scanned access points, leaving out
+// the ones moving around or opted out of geolocation
+func (l Locate) FetchLocation(ctx context.Context, scanned []domain.AccessPoint) (domain.Position, error) {
+	if l.geo == nil {
+		return domain.Position{}, ErrNoGeoLocator
 	}
 
-	for i := 0; i < 2; i++ {
-		row := strings.Split(airportRows[i], " ")
-		if strings.TrimSpace(strings.Join(row, "")) == "" {
-			return nil, errors.New("ap row incomplete")
-		}
-		ch, err := strconv.Atoi(row[1])
-		if err != nil {
-			log.Printf("conversion error: %s", err.Error())
-			return nil, err
-		}
-		m[i] = Wifi{
-			BSSID:   row[0],
-			Channel: ch,
+	accessPoints := locatable(scanned)
+	if len(accessPoints) < 2 {
+		return domain.Position{}, ErrNotEnoughAccessPoints
+	}
+	log.Printf("Locating from %d of %d access points", len(accessPoints), len(scanned))
+
+	return l.geolocate(ctx, accessPoints)
+}
+
+// FetchIPLocation returns the position of the IP address calls come from, often
+// only accurate to the city
+func (l Locate) FetchIPLocation(ctx context.Context) (domain.Position, error) {
+	if l.geo == nil {
+		return domain.Position{}, ErrNoGeoLocator
+	}
+
+	return l.geolocate(ctx, nil)
+}
+
+func (l Locate) geolocate(ctx context.Context, accessPoints []maps.WiFiAccessPoint) (domain.Position, error) {
+	result, err := l.geo.Geolocate(ctx, accessPoints)
+	if err != nil {
+		return domain.Position{}, err
+	}
+
+	return domain.Position{
+		Location: domain.Location{Lat: result.Location.Lat, Lng: result.Location.Lng},
+		Accuracy: result.Accuracy,
+	}, nil
+}
+
+// locatable returns the access points worth geolocating with, strongest first
+func locatable(scanned []domain.AccessPoint) []maps.WiFiAccessPoint {
+	var kept []domain.AccessPoint
+	seen := make(map[string]bool)
+	for _, accessPoint := range scanned {
+		bssid := strings.ToLower(accessPoint.BSSID)
+		if bssid == "" || seen[bssid] || accessPoint.IsLocallyAdministered() || accessPoint.OptsOut() {
+			continue
 		}
+		seen[bssid] = true
+		kept = append(kept, accessPoint)
 	}
 
-	accessPoints := []maps.WiFiAccessPoint{
-		{
-			Channel:    m[0].Channel,
-			MACAddress: m[0].BSSID,
-		},
-		{
-			Channel:    m[1].Channel,
-			MACAddress: m[1].BSSID,
-		},
+	// unknown strengths, reported as 0, go last
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].SignalStrength == 0 || kept[j].SignalStrength == 0 {
+			return kept[j].SignalStrength == 0 && kept[i].SignalStrength != 0
+		}
+		return kept[i].SignalStrength > kept[j].SignalStrength
+	})
+	if len(kept) > MaxAccessPoints {
+		kept = kept[:MaxAccessPoints]
 	}
 
-	return l.google.Geolocate(ctx, accessPoints)
+	accessPoints := make([]maps.WiFiAccessPoint, len(kept))
+	for i, accessPoint := range kept {
+		accessPoints[i] = maps.WiFiAccessPoint{
+			MACAddress:     strings.ToLower(accessPoint.BSSID),
+			Channel:        accessPoint.Channel,
+			SignalStrength: float64(accessPoint.SignalStrength),
+			Age:            uint64(accessPoint.Age / time.Millisecond),
+		}
+	}
+	return accessPoints
 }
 
 // FetchRestaurant ...
 func (l Locate) FetchRestaurant(ctx context.Context, req domain.SearchRequest) (domain.Place, error) {
-	currentPosition := &maps.LatLng{
-		Lat: req.Lat,
-		Lng: req.Lng,
+	shortlist, err := l.shortlist(ctx, req, 1)
+	if err != nil {
+		return domain.Place{}, err
 	}
 
-	searchRequest := &maps.NearbySearchRequest{
-		Location: currentPosition,
-		Radius:   req.Distance,
-		Type:     maps.PlaceTypeRestaurant,
+	return shortlist[0], nil
+}
+
+// FetchRestaurants returns up to req.Count distinct places, the chosen one first
+// followed by the alternatives in the order the strategy drew them
+func (l Locate) FetchRestaurants(ctx context.Context, req domain.SearchRequest) ([]domain.Place, error) {
+	return l.shortlist(ctx, req, int(req.Count))
+}
+
+func (l Locate) shortlist(ctx context.Context, req domain.SearchRequest, count int) ([]domain.Place, error) {
+	// known before searching, so a wrong name doesn't cost a search
+	strategy, err := StrategyFor(req.Strategy)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Println("Sending request to API")
-	response, err := l.google.ListRestaurants(ctx, searchRequest)
+	req, err = l.resolveNear(ctx, req)
 	if err != nil {
-		log.Printf("error from google API: %s", err.Error())
-		return domain.Place{}, err
+		return nil, err
 	}
-	log.Printf("Found %d results in response", len(response.Results))
 
-	randomPlace, err := getRandomPlace(response.Results)
+	log.Println("Sending request to places source")
+	places, err := l.places.ListRestaurants(ctx, req)
 	if err != nil {
-		log.Printf("Could not get random place, reason: %s", err.Error())
-		return domain.Place{}, err
+		log.Printf("error from places source: %s", err.Error())
+		return nil, err
+	}
+	log.Printf("Found %d results in response", len(places))
+
+	places = filterByPrice(places, req.Pricing)
+	zone, err := req.Zone()
+	if err != nil {
+		return nil, err
 	}
+	now := l.now().In(zone)
+	places = filterByOpening(places, req, now)
 
-	placeDetail := domain.PlaceFrom(randomPlace)
+	for enrichments := 0; ; enrichments++ {
+		shortlist, err := drawShortlist(strategy, req, places, count)
+		if err != nil {
+			log.Printf("Could not get random place, reason: %s", err.Error())
+			return nil, err
+		}
+		if enrichments == maxEnrichments {
+			log.Printf("Sending %s without details, the last %d places drawn were closed", shortlist[0].ID, maxEnrichments)
+			return shortlist, nil
+		}
 
-	return placeDetail, nil
+		// search results seldom carry weekly hours, the details may show the
+		// chosen place closed after all
+		chosen := l.enrich(ctx, shortlist[0])
+		if mayBeOpen(chosen, req, now) {
+			shortlist[0] = chosen
+			return shortlist, nil
+		}
+		log.Printf("%s turned out to be closed, drawing again", chosen.ID)
+		places = withoutDrawn(places, shortlist[0])
+	}
 }
 
-func getRandomPlace(places []maps.PlacesSearchResult) (maps.PlacesSearchResult, error) {
-	if len(places) == 0 {
-		return maps.PlacesSearchResult{}, errors.New("no suitable place found")
+// maxEnrichments the most chosen places whose details are fetched, each one
+// found closed being drawn again
+const maxEnrichments = 3
+
+// enrich completes the chosen place with its details, search results being
+// partial. Failing to do so isn't fatal, the place is still worth sending
+func (l Locate) enrich(ctx context.Context, place domain.Place) domain.Place {
+	if place.ID == "" {
+		return place
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	var randomPlace maps.PlacesSearchResult
-	arrayPos := rand.Intn(len(places))
+	details, err := l.places.PlaceDetails(ctx, place.ID)
+	if err != nil {
+		log.Printf("Could not fetch details of %s, reason: %s", place.ID, err.Error())
+		return place
+	}
 
-	if randomPlace = places[arrayPos]; randomPlace.Rating < 1 {
-		places = append(places[:arrayPos], places[arrayPos+1:]...)
-		return getRandomPlace(places)
+	return place.EnrichedWith(details)
+}
+
+// FetchDetails ...
+func (l Locate) FetchDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	return l.places.PlaceDetails(ctx, placeID)
+}
+
+func filterByPrice(places []domain.Place, pricing domain.Pricing) []domain.Place {
+	if pricing.IsAny() {
+		return places
+	}
+
+	var filtered []domain.Place
+	for _, place := range places {
+		if pricing.Matches(place.PriceLevel) {
+			filtered = append(filtered, place)
+		}
+	}
+	return filtered
+}
+
+// filterByOpening drops places known to be closed when the search asks for open
+// ones, places with unknown hours are kept. Hours are evaluated in the time zone
+// of now, which should be the diner's
+func filterByOpening(places []domain.Place, req domain.SearchRequest, now time.Time) []domain.Place {
+	if !req.OpenNow && req.OpenAt == nil {
+		return places
+	}
+
+	var filtered []domain.Place
+	for _, place := range places {
+		if mayBeOpen(place, req, now) {
+			filtered = append(filtered, place)
+		}
+	}
+	return filtered
+}
+
+// mayBeOpen returns whether a place isn't known to be closed when the search
+// asks for it to be open
+func mayBeOpen(place domain.Place, req domain.SearchRequest, now time.Time) bool {
+	if !req.OpenNow && req.OpenAt == nil {
+		return true
+	}
+
+	at, checkingNow := now, true
+	if req.OpenAt != nil {
+		at, checkingNow = req.OpenAt.In(now.Location()), false
+	}
+
+	open, known := place.IsOpenAt(at, checkingNow)
+	return open || !known
+}
+
+// withoutDrawn returns the distinct places but the drawn one, found the way
+// drawShortlist finds it so places without an ID aren't all taken for it.
+// places is left untouched
+func withoutDrawn(places []domain.Place, drawn domain.Place) []domain.Place {
+	kept := distinct(places)
+	if i := indexOf(kept, drawn); i >= 0 {
+		kept = append(kept[:i], kept[i+1:]...)
+	}
+	return kept
+}
+
+func drawShortlist(strategy Strategy, req domain.SearchRequest, places []domain.Place, count int) ([]domain.Place, error) {
+	if count < 1 {
+		count = 1
+	}
+	if count > domain.MaxShortlist {
+		count = domain.MaxShortlist
+	}
+
+	pool := distinct(places)
+	rnd := randomFor(req)
+
+	var shortlist []domain.Place
+	for len(shortlist) < count && len(pool) > 0 {
+		place, err := strategy.Pick(rnd, req, pool)
+		if err != nil {
+			return nil, err
+		}
+		i := indexOf(pool, place)
+		if i < 0 {
+			log.Printf("Stopped drawing, the strategy picked %s among no candidates", place.ID)
+			break
+		}
+		pool = append(pool[:i], pool[i+1:]...)
+		shortlist = append(shortlist, place)
 	}
 
-	return randomPlace, nil
+	if len(shortlist) == 0 {
+		return nil, ErrNoPlaceFound
+	}
+
+	shortlist[0].Chosen = true
+	return shortlist, nil
+}
+
+// distinct returns a copy of the places without the repeated ones, which
+// following search pages can send again
+func distinct(places []domain.Place) []domain.Place {
+	kept := make([]domain.Place, 0, len(places))
+	seen := make(map[string]bool)
+	for _, place := range places {
+		if place.ID != "" && seen[place.ID] {
+			continue
+		}
+		seen[place.ID] = true
+		kept = append(kept, place)
+	}
+	return kept
+}
+
+// indexOf returns where place is among places, -1 when it isn't. Places without
+// an ID are told apart by their contents
+func indexOf(places []domain.Place, place domain.Place) int {
+	for i := range places {
+		if place.ID != "" && places[i].ID == place.ID {
+			return i
+		}
+		if place.ID == "" && places[i].ID == "" && reflect.DeepEqual(places[i], place) {
+			return i
+		}
+	}
+	return -1
 }
 
 // FormatBool transforms a boolean into a string
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
 	mocks "github.com/romeufcrosa/where-to-eat/tests/mocks/domain/services"
 	maps "googlemaps.github.io/maps"
 
@@ -38,31 +43,279 @@ func TestFormatBool(t *testing.T) {
 }
 
 func TestFetchLocation(t *testing.T) {
+	office := domain.AccessPoint{BSSID: "68:EC:C5:C7:D9:F4", Channel: 13, SignalStrength: -43, Age: 12 * time.Millisecond}
+	neighbour := domain.AccessPoint{BSSID: "d8:07:b6:3a:11:91", Channel: 36, SignalStrength: -64}
+	unknownSignal := domain.AccessPoint{BSSID: "f4:f2:6d:12:ab:01", Channel: 6}
+	hotspot := domain.AccessPoint{BSSID: "da:a1:19:5e:00:07", SSID: "iPhone", Channel: 6, SignalStrength: -30}
+	optedOut := domain.AccessPoint{BSSID: "00:1a:2b:3c:4d:5e", SSID: "Casa_nomap", Channel: 1, SignalStrength: -35}
+
 	testCases := []struct {
-		desc        string
-		locatorMock func() GeoLocator
+		desc         string
+		accessPoints []domain.AccessPoint
+		expected     []maps.WiFiAccessPoint
 	}{
 		{
-			desc: "Fetch with just 1 AP",
-			locatorMock: func() GeoLocator {
-				locator := &mocks.GeoLocator{}
+			desc:         "Fetch with just 1 AP",
+			accessPoints: []domain.AccessPoint{office},
+		},
+		{
+			desc:         "Fetch with every AP, strongest first",
+			accessPoints: []domain.AccessPoint{unknownSignal, neighbour, office, office},
+			expected: []maps.WiFiAccessPoint{
+				{MACAddress: "68:ec:c5:c7:d9:f4", Channel: 13, SignalStrength: -43, Age: 12},
+				{MACAddress: "d8:07:b6:3a:11:91", Channel: 36, SignalStrength: -64},
+				{MACAddress: "f4:f2:6d:12:ab:01", Channel: 6},
+			},
+		},
+		{
+			desc:         "Skip hotspots and APs opted out",
+			accessPoints: []domain.AccessPoint{hotspot, optedOut, office},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			result := &maps.GeolocationResult{Location: maps.LatLng{Lat: 38.7107, Lng: -9.1365}, Accuracy: 40}
+
+			geo := &mocks.GeoLocator{}
+			geo.On("Geolocate", mock.Anything, tC.expected).Return(result, nil)
+
+			position, err := NewGeolocatorWith(geo, &mocks.PlacesSource{}).FetchLocation(context.Background(), tC.accessPoints)
+			if tC.expected == nil {
+				Expect(err).To(MatchError(ErrNotEnoughAccessPoints))
+				geo.AssertNotCalled(t, "Geolocate", mock.Anything, mock.Anything)
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(position).To(Equal(domain.Position{Location: domain.Location{Lat: 38.7107, Lng: -9.1365}, Accuracy: 40}))
+		})
+	}
+}
 
-				aPoints := []maps.WiFiAccessPoint{
-					{
-						MACAddress: "68-EC-C5-C7-D9-F4",
-						Channel:    13,
-					},
-				}
+func TestLocatableKeepsTheStrongest(t *testing.T) {
+	RegisterTestingT(t)
+	var scanned []domain.AccessPoint
+	for i := 0; i < MaxAccessPoints+5; i++ {
+		scanned = append(scanned, domain.AccessPoint{BSSID: fmt.Sprintf("00:00:00:00:00:%02x", i), SignalStrength: -90 + i})
+	}
+
+	accessPoints := locatable(scanned)
+	Expect(accessPoints).To(HaveLen(MaxAccessPoints))
+	Expect(accessPoints[0].MACAddress).To(Equal(fmt.Sprintf("00:00:00:00:00:%02x", MaxAccessPoints+4)))
+}
 
-				locator.On("Geolocate", mock.Anything, aPoints).Return()
+func TestFetchRestaurant(t *testing.T) {
+	mondayLunch := time.Date(2019, 3, 11, 13, 0, 0, 0, time.UTC)
+	dinnerOnly, _ := domain.ParseOpeningHours("Mo-Su 19:00-23:00")
 
-				return locator
+	testCases := []struct {
+		desc        string
+		pricing     domain.Pricing
+		openAt      *time.Time
+		places      []domain.Place
+		sourceError error
+		expected    string
+		expectError bool
+	}{
+		{
+			desc: "Pick the only rated place",
+			places: []domain.Place{
+				{ID: "unrated", Rating: 0},
+				{ID: "rated", Rating: 4.2},
+			},
+			expected: "rated",
+		},
+		{
+			desc:    "Only cheap places",
+			pricing: domain.PriceRange(0, 1),
+			places: []domain.Place{
+				{ID: "pricey", Rating: 4.8, PriceLevel: domain.KnownPrice(3)},
+				{ID: "cheap", Rating: 3.1, PriceLevel: domain.KnownPrice(1)},
 			},
+			expected: "cheap",
+		},
+		{
+			desc:    "Keep places of unknown price",
+			pricing: domain.PriceRange(1, 2),
+			places: []domain.Place{
+				{ID: "pricey", Rating: 4.8, PriceLevel: domain.KnownPrice(3)},
+				{ID: "unknown-price", Rating: 3.1},
+			},
+			expected: "unknown-price",
+		},
+		{
+			desc:   "Skip places closed at lunch time",
+			openAt: &mondayLunch,
+			places: []domain.Place{
+				{ID: "dinner-only", Rating: 4.8, Hours: dinnerOnly},
+				{ID: "unknown-hours", Rating: 3.1},
+			},
+			expected: "unknown-hours",
+		},
+		{
+			desc:        "No places found",
+			places:      []domain.Place{},
+			expectError: true,
+		},
+		{
+			desc:        "Source fails",
+			sourceError: errors.New("upstream down"),
+			expectError: true,
 		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
 			RegisterTestingT(t)
+			request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500, Pricing: tC.pricing, OpenAt: tC.openAt}
+
+			source := &mocks.PlacesSource{}
+			source.On("ListRestaurants", mock.Anything, request).Return(tC.places, tC.sourceError)
+			source.On("PlaceDetails", mock.Anything, tC.expected).Return(domain.Place{
+				Address: "Rua Augusta 96, 1100-053 Lisboa",
+				Phone:   "+351 21 343 1030",
+			}, nil)
+
+			locator := NewGeolocatorWith(&mocks.GeoLocator{}, source)
+			place, err := locator.FetchRestaurant(context.Background(), request)
+			if tC.expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(place.ID).To(Equal(tC.expected))
+			Expect(place.Phone).To(Equal("+351 21 343 1030"), "chosen place should be enriched with its details")
+			Expect(place.Chosen).To(BeTrue())
 		})
 	}
 }
+
+func TestFetchRestaurants(t *testing.T) {
+	RegisterTestingT(t)
+	seed := int64(3)
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500, Count: 3, Seed: &seed}
+	places := []domain.Place{
+		{ID: "a", Rating: 4.1},
+		{ID: "b", Rating: 3.7},
+		{ID: "c", Rating: 4.6},
+		{ID: "d", Rating: 2.9},
+		{ID: "unrated"},
+	}
+
+	source := &mocks.PlacesSource{}
+	source.On("ListRestaurants", mock.Anything, request).Return(places, nil)
+	source.On("PlaceDetails", mock.Anything, mock.Anything).Return(domain.Place{}, errors.New("quota exceeded"))
+
+	locator := NewGeolocatorWith(&mocks.GeoLocator{}, source)
+	shortlist, err := locator.FetchRestaurants(context.Background(), request)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(shortlist).To(HaveLen(3))
+
+	ids := make(map[string]bool)
+	for i, place := range shortlist {
+		Expect(place.Chosen).To(Equal(i == 0), "only the first place should be marked as chosen")
+		ids[place.ID] = true
+	}
+	Expect(ids).To(HaveLen(3), "places should be distinct")
+	Expect(ids).NotTo(HaveKey("unrated"))
+	Expect(places).To(HaveLen(5), "the source results should be left untouched")
+}
+
+func TestFetchRestaurantsSkipsRepeatedPlaces(t *testing.T) {
+	RegisterTestingT(t)
+	seed := int64(7)
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500, Count: 5, Strategy: UniformStrategy, Seed: &seed}
+	places := []domain.Place{
+		{ID: "a", Name: "Tasca do Chico"},
+		{ID: "a", Name: "Tasca do Chico"},
+		{Name: "Burger Baixa"},
+		{Name: "Marisqueira de Cascais"},
+	}
+
+	source := &mocks.PlacesSource{}
+	source.On("ListRestaurants", mock.Anything, request).Return(places, nil)
+	source.On("PlaceDetails", mock.Anything, mock.Anything).Return(domain.Place{}, nil)
+
+	locator := NewGeolocatorWith(&mocks.GeoLocator{}, source)
+	shortlist, err := locator.FetchRestaurants(context.Background(), request)
+	Expect(err).NotTo(HaveOccurred())
+
+	names := make(map[string]bool)
+	for _, place := range shortlist {
+		names[place.Name] = true
+	}
+	Expect(shortlist).To(HaveLen(3), "a place sent twice should be drawn once")
+	Expect(names).To(HaveLen(3), "places without an ID should all be drawn")
+}
+
+func TestFetchRestaurantInDinersTimeZone(t *testing.T) {
+	RegisterTestingT(t)
+	lunchOnly, _ := domain.ParseOpeningHours("Mo-Su 12:00-15:00")
+	request := domain.SearchRequest{Lat: 35.6812, Lng: 139.7671, Distance: 500, OpenNow: true}
+	places := []domain.Place{{ID: "ramen", Rating: 4.4, Hours: lunchOnly}}
+
+	source := &mocks.PlacesSource{}
+	source.On("ListRestaurants", mock.Anything, request).Return(places, nil)
+	source.On("PlaceDetails", mock.Anything, "ramen").Return(domain.Place{}, nil)
+
+	locator := NewGeolocatorWith(&mocks.GeoLocator{}, source)
+	// 04:00 in UTC is lunch time in Tokyo
+	locator.now = func() time.Time { return time.Date(2019, 3, 15, 4, 0, 0, 0, time.UTC) }
+
+	place, err := locator.FetchRestaurant(context.Background(), request)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(place.ID).To(Equal("ramen"))
+
+	locator.now = func() time.Time { return time.Date(2019, 3, 15, 13, 0, 0, 0, time.UTC) }
+	_, err = locator.FetchRestaurant(context.Background(), request)
+	Expect(err).To(MatchError(ErrNoPlaceFound), "closed at 22:00 in Tokyo")
+}
+
+func TestFetchRestaurantDrawsAgainWhenDetailsShowItClosed(t *testing.T) {
+	RegisterTestingT(t)
+	dinnerOnly, _ := domain.ParseOpeningHours("Mo-Su 19:00-23:00")
+	lunch := time.Date(2019, 3, 18, 13, 0, 0, 0, time.UTC)
+	places := []domain.Place{
+		{ID: "dinner", Rating: 4.8},
+		{ID: "lunch", Rating: 4.1},
+	}
+
+	source := &mocks.PlacesSource{}
+	source.On("ListRestaurants", mock.Anything, mock.Anything).Return(places, nil)
+	source.On("PlaceDetails", mock.Anything, "dinner").Return(domain.Place{Hours: dinnerOnly}, nil)
+	source.On("PlaceDetails", mock.Anything, "lunch").Return(domain.Place{Phone: "+351 21 000 0000"}, nil)
+	locator := NewGeolocatorWith(&mocks.GeoLocator{}, source)
+
+	for seed := int64(1); seed <= 8; seed++ {
+		seed := seed
+		request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500, OpenAt: &lunch, Strategy: UniformStrategy, Seed: &seed}
+
+		place, err := locator.FetchRestaurant(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(place.ID).To(Equal("lunch"), "dinner is closed at lunch once its hours are known")
+		Expect(place.Phone).To(Equal("+351 21 000 0000"))
+	}
+	source.AssertCalled(t, "PlaceDetails", mock.Anything, "dinner")
+	Expect(places).To(HaveLen(2), "the source results should be left untouched")
+}
+
+func TestWithoutDrawnKeepsOtherPlacesWithoutAnID(t *testing.T) {
+	RegisterTestingT(t)
+	places := []domain.Place{
+		{Name: "Burger Baixa"},
+		{ID: "a", Name: "Tasca do Chico"},
+		{Name: "Marisqueira de Cascais"},
+		{ID: "a", Name: "Tasca do Chico"},
+	}
+
+	Expect(withoutDrawn(places, places[0])).To(Equal([]domain.Place{
+		{ID: "a", Name: "Tasca do Chico"},
+		{Name: "Marisqueira de Cascais"},
+	}))
+	Expect(withoutDrawn(places, places[1])).To(Equal([]domain.Place{
+		{Name: "Burger Baixa"},
+		{Name: "Marisqueira de Cascais"},
+	}), "a place sent twice is drawn once")
+	Expect(places).To(HaveLen(4), "the places should be left untouched")
+}
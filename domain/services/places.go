@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// PlacesSource is implemented by any backend able to search for restaurants
+// around a location, expressed only in domain types
+type PlacesSource interface {
+	ListRestaurants(ctx context.Context, searchRequest domain.SearchRequest) ([]domain.Place, error)
+	PlaceDetails(ctx context.Context, placeID string) (domain.Place, error)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// Names of the position sources a resolver explains its choice with
+const (
+	GivenSource = "given"
+	WifiSource  = "wifi"
+	IPSource    = "ip"
+	HomeSource  = "home"
+)
+
+// ErrUnlocated error sent when no source could tell where the user is
+var ErrUnlocated error = domain.NewError(domain.NotFound, "could not find where you are")
+
+// PositionSource tells where the user is
+type PositionSource interface {
+	Position(ctx context.Context) (domain.Position, error)
+}
+
+// PositionFunc a position source computed by a function
+type PositionFunc func(ctx context.Context) (domain.Position, error)
+
+// Position ...
+func (f PositionFunc) Position(ctx context.Context) (domain.Position, error) {
+	return f(ctx)
+}
+
+// FixedPosition a position source always answering the same position, such as
+// coordinates given explicitly or a saved one
+type FixedPosition domain.Position
+
+// Position ...
+func (f FixedPosition) Position(ctx context.Context) (domain.Position, error) {
+	return domain.Position(f), nil
+}
+
+// NamedSource a position source along with the name it's explained with
+type NamedSource struct {
+	Name   string
+	Source PositionSource
+}
+
+// Attempt what asking a source gave, a position or the reason it didn't
+type Attempt struct {
+	Source   string
+	Position domain.Position
+	Err      error
+}
+
+// Resolution the position chosen along with every attempt made to find it
+type Resolution struct {
+	Position domain.Position
+	Source   string
+	Attempts []Attempt
+}
+
+// Explain returns which source was used and why, in a sentence
+func (r Resolution) Explain() string {
+	var others []string
+	for _, attempt := range r.Attempts {
+		switch {
+		case attempt.Source == r.Source:
+		case attempt.Err != nil:
+			others = append(others, fmt.Sprintf("%s failed: %s", attempt.Source, attempt.Err.Error()))
+		default:
+			others = append(others, fmt.Sprintf("%s was within %.0fm", attempt.Source, attempt.Position.Accuracy))
+		}
+	}
+
+	explanation := "not located"
+	if r.Source != "" {
+		explanation = fmt.Sprintf("located by %s within %.0fm", r.Source, r.Position.Accuracy)
+	}
+	if len(others) > 0 {
+		explanation += " (" + strings.Join(others, "; ") + ")"
+	}
+	return explanation
+}
+
+// Resolver finds where the user is by asking its sources in order, stopping at
+// the first position accurate enough and otherwise choosing the most accurate.
+// A position the user gave is taken however rough it is
+type Resolver struct {
+	sources    []NamedSource
+	goodEnough float64
+}
+
+// NewResolver returns a Resolver asking sources in order, satisfied by a
+// position known within goodEnough meters
+func NewResolver(goodEnough float64, sources ...NamedSource) Resolver {
+	return Resolver{
+		sources:    sources,
+		goodEnough: goodEnough,
+	}
+}
+
+// Resolve returns the best position found, ErrUnlocated along with the
+// attempts made when every source failed
+func (r Resolver) Resolve(ctx context.Context) (Resolution, error) {
+	var resolution Resolution
+	for _, source := range r.sources {
+		if ctx.Err() != nil {
+			return resolution, ctx.Err()
+		}
+
+		position, err := source.Source.Position(ctx)
+		resolution.Attempts = append(resolution.Attempts, Attempt{Source: source.Name, Position: position, Err: err})
+		if err != nil {
+			log.Printf("Could not locate by %s, reason: %s", source.Name, err.Error())
+			continue
+		}
+
+		if source.Name == GivenSource {
+			resolution.Position, resolution.Source = position, source.Name
+			break
+		}
+		if resolution.Source == "" || position.Accuracy < resolution.Position.Accuracy {
+			resolution.Position, resolution.Source = position, source.Name
+		}
+		if position.Accuracy <= r.goodEnough {
+			break
+		}
+	}
+
+	if resolution.Source == "" {
+		return resolution, ErrUnlocated
+	}
+	return resolution, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestResolve(t *testing.T) {
+	lisbon := domain.Location{Lat: 38.7107, Lng: -9.1365}
+	failing := PositionFunc(func(ctx context.Context) (domain.Position, error) {
+		return domain.Position{}, errors.New("no APs available")
+	})
+	askedAfter := false
+	unreachable := PositionFunc(func(ctx context.Context) (domain.Position, error) {
+		askedAfter = true
+		return domain.Position{}, nil
+	})
+
+	testCases := []struct {
+		desc        string
+		sources     []NamedSource
+		expected    string
+		explanation string
+	}{
+		{
+			desc: "Stop at a position accurate enough",
+			sources: []NamedSource{
+				{Name: WifiSource, Source: FixedPosition{Location: lisbon, Accuracy: 40}},
+				{Name: IPSource, Source: unreachable},
+			},
+			expected:    WifiSource,
+			explanation: "located by wifi within 40m",
+		},
+		{
+			desc: "Stop at a given position however rough",
+			sources: []NamedSource{
+				{Name: GivenSource, Source: FixedPosition{Location: lisbon, Accuracy: 8000}},
+				{Name: WifiSource, Source: unreachable},
+			},
+			expected:    GivenSource,
+			explanation: "located by given within 8000m",
+		},
+		{
+			desc: "Choose the most accurate after a failure",
+			sources: []NamedSource{
+				{Name: WifiSource, Source: failing},
+				{Name: IPSource, Source: FixedPosition{Location: lisbon, Accuracy: 5000}},
+				{Name: HomeSource, Source: FixedPosition{Location: lisbon, Accuracy: 1000}},
+			},
+			expected:    HomeSource,
+			explanation: "located by home within 1000m (wifi failed: no APs available; ip was within 5000m)",
+		},
+		{
+			desc:        "Fail when every source does",
+			sources:     []NamedSource{{Name: WifiSource, Source: failing}},
+			explanation: "not located (wifi failed: no APs available)",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			askedAfter = false
+
+			resolution, err := NewResolver(250, tC.sources...).Resolve(context.Background())
+			Expect(resolution.Explain()).To(Equal(tC.explanation))
+			Expect(askedAfter).To(BeFalse(), "no source should be asked past an accurate enough position")
+			if tC.expected == "" {
+				Expect(err).To(MatchError(ErrUnlocated))
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolution.Source).To(Equal(tC.expected))
+			Expect(resolution.Position.Location).To(Equal(lisbon))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// Names of the selection strategies a search can ask for, as validated by
+// domain.Strategies
+const (
+	UniformStrategy  = "uniform"
+	RatingStrategy   = "rating"
+	DistanceStrategy = "distance"
+	SoftmaxStrategy  = "softmax"
+
+	// DefaultStrategy used when a search doesn't name one
+	DefaultStrategy = RatingStrategy
+
+	maxRating          = 5
+	softmaxTemperature = 0.25
+	defaultDecay       = 1000
+)
+
+var (
+	// ErrNoPlaceFound error sent when there is nothing to pick from
+	ErrNoPlaceFound error = domain.NewError(domain.NotFound, "no suitable place found")
+	// ErrUnknownStrategy error sent when a search names a strategy that doesn't exist
+	ErrUnknownStrategy error = domain.NewError(domain.Validation, "unknown selection strategy")
+
+	strategies = map[string]Strategy{
+		UniformStrategy:  WeightFunc(uniformWeight),
+		RatingStrategy:   WeightFunc(ratingWeight),
+		DistanceStrategy: WeightFunc(distanceWeight),
+		SoftmaxStrategy:  WeightFunc(softmaxWeight),
+	}
+)
+
+// Strategy picks one place among the candidates of a search
+type Strategy interface {
+	Pick(rnd *rand.Rand, req domain.SearchRequest, places []domain.Place) (domain.Place, error)
+}
+
+// WeightFunc a strategy picking places with a probability proportional to their weight
+type WeightFunc func(req domain.SearchRequest, place domain.Place) float64
+
+// Pick ...
+func (weight WeightFunc) Pick(rnd *rand.Rand, req domain.SearchRequest, places []domain.Place) (domain.Place, error) {
+	if len(places) == 0 {
+		return domain.Place{}, ErrNoPlaceFound
+	}
+
+	weights := make([]float64, len(places))
+	var total float64
+	for i, place := range places {
+		weights[i] = math.Max(weight(req, place), 0)
+		total += weights[i]
+	}
+
+	// Nothing stands out, e.g. a source without ratings, fall back to uniform
+	if total == 0 || math.IsInf(total, 0) || math.IsNaN(total) {
+		return places[rnd.Intn(len(places))], nil
+	}
+
+	target := rnd.Float64() * total
+	for i, place := range places {
+		if target < weights[i] {
+			return place, nil
+		}
+		target -= weights[i]
+	}
+
+	return places[len(places)-1], nil
+}
+
+// StrategyFor returns the strategy with the given name, or the default one for an empty name
+func StrategyFor(name string) (Strategy, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, ErrUnknownStrategy
+	}
+	return strategy, nil
+}
+
+func randomFor(req domain.SearchRequest) *rand.Rand {
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+func uniformWeight(req domain.SearchRequest, place domain.Place) float64 {
+	return 1
+}
+
+func ratingWeight(req domain.SearchRequest, place domain.Place) float64 {
+	return float64(place.Rating)
+}
+
+func distanceWeight(req domain.SearchRequest, place domain.Place) float64 {
+	return math.Exp(-req.Origin().DistanceTo(place.Location) / decayFor(req))
+}
+
+// softmaxWeight favours well rated places close by, in a single composite score
+func softmaxWeight(req domain.SearchRequest, place domain.Place) float64 {
+	rating := float64(place.Rating) / maxRating
+	proximity := 1 - math.Min(req.Origin().DistanceTo(place.Location)/(3*decayFor(req)), 1)
+
+	return math.Exp((rating + proximity) / softmaxTemperature)
+}
+
+func decayFor(req domain.SearchRequest) float64 {
+	if req.Distance == 0 {
+		return defaultDecay
+	}
+	return float64(req.Distance) / 3
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"math/rand"
+	"testing"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStrategies(t *testing.T) {
+	origin := domain.Location{Lat: 38.7107, Lng: -9.1365}
+	places := []domain.Place{
+		{ID: "next-door", Rating: 3.0, Location: domain.Location{Lat: 38.7108, Lng: -9.1365}},
+		{ID: "across-town", Rating: 4.9, Location: domain.Location{Lat: 38.7400, Lng: -9.1600}},
+		{ID: "unrated", Rating: 0, Location: domain.Location{Lat: 38.7110, Lng: -9.1370}},
+	}
+
+	testCases := []struct {
+		desc       string
+		strategy   string
+		favourite  string
+		neverPicks string
+	}{
+		{
+			desc:       "Rating weighted never picks unrated places",
+			strategy:   RatingStrategy,
+			favourite:  "across-town",
+			neverPicks: "unrated",
+		},
+		{
+			desc:      "Distance decay favours the closest place",
+			strategy:  DistanceStrategy,
+			favourite: "next-door",
+		},
+		{
+			desc:      "Softmax favours close and well rated places",
+			strategy:  SoftmaxStrategy,
+			favourite: "next-door",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			req := domain.SearchRequest{Lat: origin.Lat, Lng: origin.Lng, Distance: 1000, Strategy: tC.strategy}
+
+			strategy, err := StrategyFor(tC.strategy)
+			Expect(err).NotTo(HaveOccurred())
+
+			picks := make(map[string]int)
+			rnd := rand.New(rand.NewSource(42))
+			for i := 0; i < 1000; i++ {
+				place, err := strategy.Pick(rnd, req, places)
+				Expect(err).NotTo(HaveOccurred())
+				picks[place.ID]++
+			}
+
+			for id, count := range picks {
+				if id != tC.favourite {
+					Expect(picks[tC.favourite]).To(BeNumerically(">", count))
+				}
+			}
+			if tC.neverPicks != "" {
+				Expect(picks).NotTo(HaveKey(tC.neverPicks))
+			}
+		})
+	}
+}
+
+func TestStrategySeeding(t *testing.T) {
+	RegisterTestingT(t)
+	seed := int64(7)
+	req := domain.SearchRequest{Strategy: UniformStrategy, Seed: &seed}
+	places := []domain.Place{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	strategy, err := StrategyFor(req.Strategy)
+	Expect(err).NotTo(HaveOccurred())
+
+	first, _ := strategy.Pick(randomFor(req), req, places)
+	for i := 0; i < 10; i++ {
+		again, _ := strategy.Pick(randomFor(req), req, places)
+		Expect(again).To(Equal(first), "same seed should pick the same place")
+	}
+}
+
+func TestUnknownStrategy(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := StrategyFor("coin-toss")
+	Expect(err).To(MatchError(ErrUnknownStrategy))
+
+	strategy, err := StrategyFor("")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(strategy).NotTo(BeNil())
+
+	_, err = strategy.Pick(rand.New(rand.NewSource(1)), domain.SearchRequest{}, nil)
+	Expect(err).To(MatchError(ErrNoPlaceFound))
+}
+
+func TestStrategiesAreValidated(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(domain.Strategies).To(HaveLen(len(strategies)))
+	for _, name := range domain.Strategies {
+		Expect(strategies).To(HaveKey(name), "searches naming %s should pass validation", name)
+	}
+}
@@ -0,0 +1,92 @@
+// Package cache provides a caching decorator for places sources, so searches
+// repeated from about the same spot are answered without calling upstream
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"github.com/romeufcrosa/where-to-eat/domain/services"
+)
+
+// Store a key value store whose entries expire
+type Store interface {
+	// Get returns the value stored under key, found is false when missing or expired
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// placeType the only type of place searched for
+const placeType = "restaurant"
+
+// PlacesSource caches the nearby searches of a places source
+type PlacesSource struct {
+	source    services.PlacesSource
+	provider  string
+	store     Store
+	ttl       time.Duration
+	precision int
+}
+
+// NewPlacesSource returns source with its nearby searches kept in store for
+// ttl, shared by searches within the same geohash cell of precision characters.
+// Entries are kept apart by provider, the name of the source, since places and
+// their IDs differ between providers
+func NewPlacesSource(source services.PlacesSource, provider string, store Store, ttl time.Duration, precision int) PlacesSource {
+	return PlacesSource{
+		source:    source,
+		provider:  provider,
+		store:     store,
+		ttl:       ttl,
+		precision: precision,
+	}
+}
+
+// ListRestaurants returns the cached places for the search cell, searching
+// the source when there are none. The cache failing never fails the search
+func (p PlacesSource) ListRestaurants(ctx context.Context, req domain.SearchRequest) ([]domain.Place, error) {
+	key := p.key(req)
+
+	cached, found, err := p.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("Could not read cache entry %s: %s", key, err.Error())
+	}
+	if found {
+		var places []domain.Place
+		if err := json.Unmarshal(cached, &places); err == nil {
+			return places, nil
+		}
+		log.Printf("Ignoring unreadable cache entry %s", key)
+	}
+
+	places, err := p.source.ListRestaurants(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(places)
+	if err == nil {
+		err = p.store.Set(ctx, key, data, p.ttl)
+	}
+	if err != nil {
+		log.Printf("Could not write cache entry %s: %s", key, err.Error())
+	}
+
+	return places, nil
+}
+
+// PlaceDetails ...
+func (p PlacesSource) PlaceDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	return p.source.PlaceDetails(ctx, placeID)
+}
+
+// key identifies the searches answered by the same upstream query. Opening
+// filters are evaluated locally and don't matter
+func (p PlacesSource) key(req domain.SearchRequest) string {
+	return fmt.Sprintf("nearby:%s:%s:%d:%s:%s",
+		p.provider, Geohash(req.Lat, req.Lng, p.precision), req.Distance, placeType, req.Pricing)
+}
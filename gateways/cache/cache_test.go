@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	mocks "github.com/romeufcrosa/where-to-eat/tests/mocks/domain/services"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestGeohash(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Geohash(57.64911, 10.40744, 11)).To(Equal("u4pruydqqvj"))
+	Expect(Geohash(38.7107, -9.1365, 7)).To(Equal(Geohash(38.7110, -9.1362, 7)), "the same office should share a cell")
+	Expect(Geohash(38.7107, -9.1365, 7)).NotTo(Equal(Geohash(38.7223, -9.1393, 7)), "across town should not")
+}
+
+func TestPlacesSourceCachesNearbySearches(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500}
+	desk := domain.SearchRequest{Lat: 38.7110, Lng: -9.1362, Distance: 500}
+	places := []domain.Place{{ID: "a", Name: "Tasca", Rating: 4.1}}
+
+	source := &mocks.PlacesSource{}
+	source.On("ListRestaurants", mock.Anything, request).Return(places, nil).Once()
+	cached := NewPlacesSource(source, "google", NewLRU(8), time.Minute, 7)
+
+	first, err := cached.ListRestaurants(ctx, request)
+	Expect(err).NotTo(HaveOccurred())
+	second, err := cached.ListRestaurants(ctx, desk)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(second).To(Equal(first))
+	source.AssertNumberOfCalls(t, "ListRestaurants", 1)
+
+	cheap := request
+	cheap.Pricing = domain.PriceRange(0, 1)
+	source.On("ListRestaurants", mock.Anything, cheap).Return([]domain.Place{}, nil).Once()
+	_, err = cached.ListRestaurants(ctx, cheap)
+	Expect(err).NotTo(HaveOccurred())
+	source.AssertNumberOfCalls(t, "ListRestaurants", 2)
+}
+
+func TestPlacesSourceDoesNotCacheFailures(t *testing.T) {
+	RegisterTestingT(t)
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500}
+	store := NewLRU(8)
+
+	source := &mocks.PlacesSource{}
+	source.On("ListRestaurants", mock.Anything, request).Return(nil, errors.New("upstream down"))
+
+	_, err := NewPlacesSource(source, "google", store, time.Minute, 7).ListRestaurants(context.Background(), request)
+	Expect(err).To(HaveOccurred())
+	Expect(store.Len()).To(BeZero())
+}
+
+func TestPlacesSourceKeepsProvidersApart(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500}
+	store := NewLRU(8)
+
+	google := &mocks.PlacesSource{}
+	google.On("ListRestaurants", mock.Anything, request).Return([]domain.Place{{ID: "ChIJ0a"}}, nil)
+	osm := &mocks.PlacesSource{}
+	osm.On("ListRestaurants", mock.Anything, request).Return([]domain.Place{{ID: "node/42"}}, nil)
+
+	Expect(NewPlacesSource(google, "google", store, time.Minute, 7).ListRestaurants(ctx, request)).To(Equal([]domain.Place{{ID: "ChIJ0a"}}))
+	Expect(NewPlacesSource(osm, "osm", store, time.Minute, 7).ListRestaurants(ctx, request)).To(Equal([]domain.Place{{ID: "node/42"}}), "Google places shouldn't be served as OSM's")
+	osm.AssertNumberOfCalls(t, "ListRestaurants", 1)
+}
+
+func TestLRU(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	now := time.Date(2019, 3, 15, 12, 0, 0, 0, time.UTC)
+	lru := NewLRU(2)
+	lru.now = func() time.Time { return now }
+
+	Expect(lru.Set(ctx, "a", []byte("1"), time.Minute)).To(Succeed())
+	Expect(lru.Set(ctx, "b", []byte("2"), time.Minute)).To(Succeed())
+	_, found, _ := lru.Get(ctx, "a")
+	Expect(found).To(BeTrue())
+
+	Expect(lru.Set(ctx, "c", []byte("3"), time.Minute)).To(Succeed())
+	_, found, _ = lru.Get(ctx, "b")
+	Expect(found).To(BeFalse(), "the least recently used entry should be evicted")
+
+	now = now.Add(time.Minute)
+	_, found, _ = lru.Get(ctx, "a")
+	Expect(found).To(BeFalse(), "entries should expire after their ttl")
+	Expect(lru.Len()).To(Equal(1))
+}
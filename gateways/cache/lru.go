@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRU an in-memory Store evicting the least recently used entry once full
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	recency *list.List
+	now     func() time.Time
+}
+
+// NewLRU returns an empty LRU holding up to size entries
+func NewLRU(size int) *LRU {
+	if size < 1 {
+		size = 1
+	}
+
+	return &LRU{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		recency: list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get ...
+func (l *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := element.Value.(*entry)
+	if !l.now().Before(e.expires) {
+		l.remove(element)
+		return nil, false, nil
+	}
+
+	l.recency.MoveToFront(element)
+	return e.value, true, nil
+}
+
+// Set ...
+func (l *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := l.now().Add(ttl)
+	if element, ok := l.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value, e.expires = value, expires
+		l.recency.MoveToFront(element)
+		return nil
+	}
+
+	l.entries[key] = l.recency.PushFront(&entry{key: key, value: value, expires: expires})
+	for l.recency.Len() > l.size {
+		l.remove(l.recency.Back())
+	}
+	return nil
+}
+
+// Len returns how many entries are held, expired ones included until evicted
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.recency.Len()
+}
+
+func (l *LRU) remove(element *list.Element) {
+	l.recency.Remove(element)
+	delete(l.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	redisTimeout     = time.Second
+	redisIdleTimeout = 4 * time.Minute
+	redisMaxIdle     = 4
+)
+
+// Redis a Store backed by any server speaking the redis protocol, letting
+// several instances share their searches
+type Redis struct {
+	pool *redis.Pool
+}
+
+// NewRedis returns a Redis store connecting to address when first used
+func NewRedis(address string) *Redis {
+	return &Redis{
+		pool: &redis.Pool{
+			MaxIdle:     redisMaxIdle,
+			IdleTimeout: redisIdleTimeout,
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", address,
+					redis.DialConnectTimeout(redisTimeout),
+					redis.DialReadTimeout(redisTimeout),
+					redis.DialWriteTimeout(redisTimeout),
+				)
+			},
+		},
+	}
+}
+
+// Get ...
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set ...
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("SET", key, value, "PX", int64(ttl/time.Millisecond))
+	return err
+}
+
+// Close closes the idle connections
+func (r *Redis) Close() error {
+	return r.pool.Close()
+}
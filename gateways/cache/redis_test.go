@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// fakeRedis speaks just enough of the redis protocol for GET and SET with PX,
+// its clock moved by hand so expiry can be tested
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	now      time.Time
+	values   map[string]string
+	expiries map[string]time.Time
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &fakeRedis{
+		listener: listener,
+		now:      time.Now(),
+		values:   make(map[string]string),
+		expiries: make(map[string]time.Time),
+	}
+	go server.serve()
+	return server
+}
+
+func (f *fakeRedis) Addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeRedis) Close() {
+	f.listener.Close()
+}
+
+func (f *fakeRedis) FastForward(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		io.WriteString(conn, f.reply(args))
+	}
+}
+
+func (f *fakeRedis) reply(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := f.values[args[1]]
+		if expiry, expires := f.expiries[args[1]]; !ok || (expires && !f.now.Before(expiry)) {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		f.values[args[1]] = args[2]
+		delete(f.expiries, args[1])
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, _ := strconv.Atoi(args[4])
+			f.expiries[args[1]] = f.now.Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	count, err := readLength(reader, '*')
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		length, err := readLength(reader, '$')
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:length])
+	}
+	return args, nil
+}
+
+func readLength(reader *bufio.Reader, prefix byte) (int, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	if len(line) < 3 || line[0] != prefix {
+		return 0, fmt.Errorf("unexpected %q", line)
+	}
+	return strconv.Atoi(strings.TrimSpace(line[1:]))
+}
+
+func TestRedis(t *testing.T) {
+	RegisterTestingT(t)
+	server := startFakeRedis(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	store := NewRedis(server.Addr())
+	defer store.Close()
+
+	_, found, err := store.Get(ctx, "nearby:eyckpx0:500")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(found).To(BeFalse())
+
+	Expect(store.Set(ctx, "nearby:eyckpx0:500", []byte(`[{"id":"a"}]`), time.Minute)).To(Succeed())
+	value, found, err := store.Get(ctx, "nearby:eyckpx0:500")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(string(value)).To(Equal(`[{"id":"a"}]`))
+
+	server.FastForward(time.Minute)
+	_, found, err = store.Get(ctx, "nearby:eyckpx0:500")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(found).To(BeFalse(), "entries should expire after their ttl")
+
+	server.Close()
+	store.Close()
+	_, _, err = NewRedis(server.Addr()).Get(ctx, "nearby:eyckpx0:500")
+	Expect(err).To(HaveOccurred())
+}
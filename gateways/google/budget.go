@@ -0,0 +1,232 @@
+package google
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"golang.org/x/time/rate"
+)
+
+// Endpoint a Google API calls are budgeted for
+type Endpoint string
+
+// Budgeted endpoints
+const (
+	NearbyEndpoint    = Endpoint("nearby")
+	DetailsEndpoint   = Endpoint("details")
+	GeolocateEndpoint = Endpoint("geolocate")
+	GeocodeEndpoint   = Endpoint("geocode")
+)
+
+// Endpoints every budgeted endpoint
+var Endpoints = []Endpoint{NearbyEndpoint, DetailsEndpoint, GeolocateEndpoint, GeocodeEndpoint}
+
+var (
+	remainingMetrics = expvar.NewMap("google_quota_remaining")
+	callsMetrics     = expvar.NewMap("google_calls")
+)
+
+// Limit the rate and daily quota of an endpoint, a zero Daily is unlimited
+type Limit struct {
+	QPS   float64
+	Burst int
+	Daily int
+}
+
+// Budget rate limits calls per endpoint with a token bucket and counts them
+// against a daily quota, reset at midnight Pacific Time like Google's
+type Budget struct {
+	mu       sync.Mutex
+	limiters map[Endpoint]*rate.Limiter
+	daily    map[Endpoint]int
+	used     map[Endpoint]int
+	day      string
+	now      func() time.Time
+	zone     *time.Location
+}
+
+// LimitsFrom returns the same rate for every endpoint along with their daily
+// quotas, keyed by endpoint name
+func LimitsFrom(qps float64, daily map[string]int) map[Endpoint]Limit {
+	limits := make(map[Endpoint]Limit)
+	for _, endpoint := range Endpoints {
+		limits[endpoint] = Limit{QPS: qps, Daily: daily[string(endpoint)]}
+	}
+	return limits
+}
+
+// NewBudget returns a budget enforcing the given limits, endpoints without a
+// limit aren't restricted
+func NewBudget(limits map[Endpoint]Limit) *Budget {
+	zone, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		zone = time.UTC
+	}
+
+	budget := &Budget{
+		used: make(map[Endpoint]int),
+		now:  time.Now,
+		zone: zone,
+	}
+	budget.SetLimits(limits)
+
+	return budget
+}
+
+// SetLimits replaces the limits, keeping the calls already spent today
+func (b *Budget) SetLimits(limits map[Endpoint]Limit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.limiters = make(map[Endpoint]*rate.Limiter)
+	b.daily = make(map[Endpoint]int)
+	for endpoint, limit := range limits {
+		if limit.QPS > 0 {
+			burst := limit.Burst
+			if burst < 1 {
+				burst = 1
+			}
+			b.limiters[endpoint] = rate.NewLimiter(rate.Limit(limit.QPS), burst)
+		}
+		if limit.Daily > 0 {
+			b.daily[endpoint] = limit.Daily
+			remainingMetrics.Set(string(endpoint), remainingVar(limit.Daily-b.used[endpoint]))
+		}
+	}
+}
+
+// Take waits for the endpoint's rate limit then spends one call of its daily
+// quota, failing with ErrQuotaExceeded once none is left
+func (b *Budget) Take(ctx context.Context, endpoint Endpoint) error {
+	if b == nil {
+		return nil
+	}
+
+	if b.exhausted(endpoint) {
+		return domain.ErrQuotaExceeded
+	}
+
+	if limiter := b.limiter(endpoint); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return b.spend(endpoint)
+}
+
+// Remaining returns how many calls are left today, -1 when unlimited
+func (b *Budget) Remaining(endpoint Endpoint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetDaily()
+	return b.remaining(endpoint)
+}
+
+func (b *Budget) exhausted(endpoint Endpoint) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetDaily()
+	return b.spent(endpoint)
+}
+
+func (b *Budget) limiter(endpoint Endpoint) *rate.Limiter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.limiters[endpoint]
+}
+
+func (b *Budget) spend(endpoint Endpoint) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetDaily()
+	if b.spent(endpoint) {
+		return domain.ErrQuotaExceeded
+	}
+
+	b.used[endpoint]++
+	callsMetrics.Add(string(endpoint), 1)
+	if limit, ok := b.daily[endpoint]; ok {
+		remainingMetrics.Set(string(endpoint), remainingVar(limit-b.used[endpoint]))
+	}
+	return nil
+}
+
+// spent tells whether the endpoint's daily quota is used up, which a reload
+// lowering the quota below today's calls leaves it beyond
+func (b *Budget) spent(endpoint Endpoint) bool {
+	limit, ok := b.daily[endpoint]
+	return ok && b.used[endpoint] >= limit
+}
+
+func (b *Budget) remaining(endpoint Endpoint) int {
+	limit, ok := b.daily[endpoint]
+	if !ok {
+		return -1
+	}
+	if b.spent(endpoint) {
+		return 0
+	}
+	return limit - b.used[endpoint]
+}
+
+func (b *Budget) resetDaily() {
+	today := b.now().In(b.zone).Format("2006-01-02")
+	if today == b.day {
+		return
+	}
+
+	b.day = today
+	b.used = make(map[Endpoint]int)
+	for endpoint, limit := range b.daily {
+		remainingMetrics.Set(string(endpoint), remainingVar(limit))
+	}
+}
+
+func remainingVar(remaining int) *expvar.Int {
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	value := new(expvar.Int)
+	value.Set(int64(remaining))
+	return value
+}
+
+// limits the budget and deadline applied to every call of an endpoint
+type limits struct {
+	budget   *Budget
+	timeouts map[Endpoint]time.Duration
+}
+
+// begin spends a call of the endpoint's budget and bounds ctx by its timeout,
+// cancel has to be called once the call is done
+func (l limits) begin(ctx context.Context, endpoint Endpoint) (context.Context, context.CancelFunc, error) {
+	if err := l.budget.Take(ctx, endpoint); err != nil {
+		return ctx, func() {}, err
+	}
+
+	if timeout, ok := l.timeouts[endpoint]; ok && timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
+
+// TimeoutsFrom returns the timeouts keyed by endpoint name
+func TimeoutsFrom(timeouts map[string]time.Duration) map[Endpoint]time.Duration {
+	byEndpoint := make(map[Endpoint]time.Duration)
+	for _, endpoint := range Endpoints {
+		if timeout, ok := timeouts[string(endpoint)]; ok {
+			byEndpoint[endpoint] = timeout
+		}
+	}
+	return byEndpoint
+}
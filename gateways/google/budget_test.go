@@ -0,0 +1,69 @@
+package google
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"googlemaps.github.io/maps"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBudgetDailyQuota(t *testing.T) {
+	RegisterTestingT(t)
+	budget := NewBudget(map[Endpoint]Limit{DetailsEndpoint: {Daily: 2}})
+	today := time.Date(2019, 3, 15, 12, 0, 0, 0, time.UTC)
+	budget.now = func() time.Time { return today }
+
+	Expect(budget.Take(context.Background(), DetailsEndpoint)).To(Succeed())
+	Expect(budget.Take(context.Background(), DetailsEndpoint)).To(Succeed())
+	Expect(budget.Remaining(DetailsEndpoint)).To(Equal(0))
+	Expect(budget.Take(context.Background(), DetailsEndpoint)).To(MatchError(domain.ErrQuotaExceeded))
+	Expect(budget.Remaining(NearbyEndpoint)).To(Equal(-1), "endpoints without a quota are unlimited")
+
+	// 08:00 UTC is midnight in California, where Google resets quotas
+	today = time.Date(2019, 3, 16, 8, 0, 0, 0, time.UTC)
+	Expect(budget.Remaining(DetailsEndpoint)).To(Equal(2))
+	Expect(budget.Take(context.Background(), DetailsEndpoint)).To(Succeed())
+}
+
+func TestBudgetQuotaLoweredBelowTheCallsSpent(t *testing.T) {
+	RegisterTestingT(t)
+	budget := NewBudget(map[Endpoint]Limit{GeocodeEndpoint: {Daily: 10}})
+	today := time.Date(2019, 3, 15, 12, 0, 0, 0, time.UTC)
+	budget.now = func() time.Time { return today }
+	for i := 0; i < 5; i++ {
+		Expect(budget.Take(context.Background(), GeocodeEndpoint)).To(Succeed())
+	}
+
+	budget.SetLimits(map[Endpoint]Limit{GeocodeEndpoint: {Daily: 3}})
+	Expect(budget.Take(context.Background(), GeocodeEndpoint)).To(MatchError(domain.ErrQuotaExceeded))
+	Expect(budget.Remaining(GeocodeEndpoint)).To(Equal(0))
+	Expect(remainingMetrics.Get(string(GeocodeEndpoint)).String()).To(Equal("0"), "the metric shouldn't go negative")
+}
+
+func TestBudgetRateLimit(t *testing.T) {
+	RegisterTestingT(t)
+	budget := NewBudget(map[Endpoint]Limit{NearbyEndpoint: {QPS: 1}})
+
+	Expect(budget.Take(context.Background(), NearbyEndpoint)).To(Succeed())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	Expect(budget.Take(ctx, NearbyEndpoint)).NotTo(Succeed(), "the next token is a second away")
+	Expect(budget.Take(context.Background(), GeocodeEndpoint)).To(Succeed(), "endpoints have their own bucket")
+}
+
+func TestBudgetedPaginator(t *testing.T) {
+	RegisterTestingT(t)
+	budget := NewBudget(map[Endpoint]Limit{NearbyEndpoint: {Daily: 2}})
+	search := &pagedSearch{pages: threePages()}
+	paginator := NewPaginator(limitedSearcher{client: search, limits: limits{budget: budget}}, DefaultMaxPages, 0)
+
+	results, err := paginator.All(context.Background(), &maps.NearbySearchRequest{})
+	Expect(err).To(MatchError(domain.ErrQuotaExceeded))
+	Expect(results).To(HaveLen(3), "pages fetched before the quota ran out are kept")
+	Expect(search.requests).To(HaveLen(2))
+}
@@ -4,6 +4,7 @@ package google
 import (
 	"context"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,25 +14,65 @@ import (
 
 // GeoGateway ...
 type GeoGateway struct {
-	client *maps.Client
+	client    *maps.Client
+	paginator Paginator
+	limits    limits
+}
+
+// Option configures a GeoGateway
+type Option func(*GeoGateway)
+
+// WithMaxPages caps how many Nearby Search pages are followed per search
+func WithMaxPages(maxPages int) Option {
+	return func(g *GeoGateway) {
+		g.paginator = NewPaginator(g.client, maxPages, g.paginator.pageDelay)
+	}
+}
+
+// WithBudget rate limits the calls and counts them against a daily quota
+func WithBudget(budget *Budget) Option {
+	return func(g *GeoGateway) {
+		g.limits.budget = budget
+	}
+}
+
+// WithTimeouts bounds every call to an endpoint by its timeout
+func WithTimeouts(timeouts map[Endpoint]time.Duration) Option {
+	return func(g *GeoGateway) {
+		g.limits.timeouts = timeouts
+	}
 }
 
 // NewGoogleGateway ...
-func NewGoogleGateway(client *maps.Client) GeoGateway {
+func NewGoogleGateway(client *maps.Client, options ...Option) GeoGateway {
 	gg := GeoGateway{
-		client: client,
+		client:    client,
+		paginator: NewPaginator(client, DefaultMaxPages, DefaultPageDelay),
+	}
+
+	for _, option := range options {
+		option(&gg)
 	}
+	gg.paginator.client = limitedSearcher{client: client, limits: gg.limits}
 
 	return gg
 }
 
 // Geolocate ...
 func (g *GeoGateway) Geolocate(ctx context.Context, accessPoints []maps.WiFiAccessPoint) (*maps.GeolocationResult, error) {
+	// the IP address is only considered when asked for, so a fix from it isn't
+	// taken for a Wi-Fi one
 	gRequest := &maps.GeolocationRequest{
-		ConsiderIP:       true,
+		ConsiderIP:       len(accessPoints) == 0,
 		WiFiAccessPoints: accessPoints,
 	}
 
+	ctx, cancel, err := g.limits.begin(ctx, GeolocateEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
 	result, err := g.client.Geolocate(ctx, gRequest)
 	if err != nil {
 		log.Printf("Geolocate failed: %s", err.Error())
@@ -42,19 +83,81 @@ func (g *GeoGateway) Geolocate(ctx context.Context, accessPoints []maps.WiFiAcce
 }
 
 // ListRestaurants ...
-func (g *GeoGateway) ListRestaurants(ctx context.Context, searchRequest *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
-	restaurants, err := g.client.NearbySearch(context.Background(), searchRequest)
+func (g *GeoGateway) ListRestaurants(ctx context.Context, searchRequest domain.SearchRequest) ([]domain.Place, error) {
+	// opening filters are applied locally, upstream would also leave out the
+	// places whose hours are unknown
+	nearbyRequest := &maps.NearbySearchRequest{
+		Location: &maps.LatLng{
+			Lat: searchRequest.Lat,
+			Lng: searchRequest.Lng,
+		},
+		Radius: searchRequest.Distance,
+		Type:   maps.PlaceTypeRestaurant,
+	}
+	if !searchRequest.Pricing.IsAny() {
+		min, max := searchRequest.Pricing.Bounds()
+		nearbyRequest.MinPrice = maps.PriceLevel(strconv.Itoa(min))
+		nearbyRequest.MaxPrice = maps.PriceLevel(strconv.Itoa(max))
+	}
+
+	zone, err := searchRequest.Zone()
 	if err != nil {
-		return maps.PlacesSearchResponse{}, err
+		return nil, err
 	}
+	today := time.Now().In(zone).Weekday()
 
-	return restaurants, nil
+	results, err := g.paginator.All(ctx, nearbyRequest)
+	if err != nil && len(results) == 0 {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Stopped paging Nearby Search: %s", err.Error())
+	}
+
+	places := make([]domain.Place, 0, len(results))
+	for _, result := range results {
+		places = append(places, placeFrom(result, today))
+	}
+
+	return places, nil
+}
+
+// detailsFields are the only fields newPlaceResponse reads, Google bills
+// Place Details by the data it returns
+var detailsFields = []maps.PlaceDetailsFieldMask{
+	maps.PlaceDetailsFieldMaskPlaceID,
+	maps.PlaceDetailsFieldMaskFormattedAddress,
+	maps.PlaceDetailsFieldMaskGeometryLocation,
+	maps.PlaceDetailsFieldMaskName,
+	maps.PlaceDetailsFieldMaskFormattedPhoneNumber,
+	maps.PlaceDetailsFieldMaskWebsite,
+	maps.PlaceDetailsFieldMaskReviews,
+	maps.PlaceDetailsFieldMaskPhotos,
+	maps.PlaceDetailsFieldMaskPriceLevel,
+	maps.PlaceDetailsFieldMaskRatings,
+	maps.PlaceDetailsFieldMaskOpeningHours,
+	maps.PlaceDetailsFieldMaskUTCOffset,
+	maps.PlaceDetailsFieldMaskTypes,
 }
 
 // PlaceDetails ...
-func (g *GeoGateway) PlaceDetails(ctx context.Context, detailsRequest *maps.PlaceDetailsRequest) (domain.Place, error) {
-	placeDetails, err := g.client.PlaceDetails(context.Background(), detailsRequest)
+func (g *GeoGateway) PlaceDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	detailsRequest := &maps.PlaceDetailsRequest{
+		PlaceID: placeID,
+		Fields:  detailsFields,
+	}
+
+	ctx, cancel, err := g.limits.begin(ctx, DetailsEndpoint)
+	if err != nil {
+		return domain.Place{}, err
+	}
+	defer cancel()
+
+	placeDetails, err := g.client.PlaceDetails(ctx, detailsRequest)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "maps: NOT_FOUND") {
+			return domain.Place{}, domain.WrapError(domain.NotFound, err)
+		}
 		return domain.Place{}, err
 	}
 
@@ -65,27 +168,171 @@ func (g *GeoGateway) PlaceDetails(ctx context.Context, detailsRequest *maps.Plac
 	return response, nil
 }
 
+// Geocode ...
+func (g *GeoGateway) Geocode(ctx context.Context, geocodingRequest *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+	ctx, cancel, err := g.limits.begin(ctx, GeocodeEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	return g.client.Geocode(ctx, geocodingRequest)
+}
+
+// Forward returns the places an address or place name refers to, best match first
+func (g *GeoGateway) Forward(ctx context.Context, query string) ([]domain.Match, error) {
+	results, err := g.Geocode(ctx, &maps.GeocodingRequest{Address: query})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]domain.Match, 0, len(results))
+	for _, result := range results {
+		matches = append(matches, domain.Match{
+			Address: result.FormattedAddress,
+			Position: domain.Position{
+				Location: locationFrom(result.Geometry.Location),
+				Accuracy: accuracyOf(result.Geometry.Viewport),
+			},
+		})
+	}
+	return matches, nil
+}
+
+// accuracyOf returns how far from its center a geocoding viewport reaches
+func accuracyOf(viewport maps.LatLngBounds) float64 {
+	return locationFrom(viewport.NorthEast).DistanceTo(locationFrom(viewport.SouthWest)) / 2
+}
+
 func newPlaceResponse(details maps.PlaceDetailsResult) (domain.Place, error) {
+	now := time.Now()
+	if details.UTCOffset != nil {
+		now = now.In(time.FixedZone("", *details.UTCOffset*60))
+	}
+
 	place := domain.Place{}
+	place.ID = details.PlaceID
 	place.Address = details.FormattedAddress
-	place.Location = details.Geometry.Location
+	place.Location = locationFrom(details.Geometry.Location)
 	place.Name = details.Name
 	place.Phone = details.FormattedPhoneNumber
-	place.PriceLevel = details.PriceLevel
+	place.Website = details.Website
+	place.Reviews = reviewsFrom(details.Reviews)
+	place.Photos = photosFrom(details.Photos)
+	place.PriceLevel = priceLevelFrom(details.PriceLevel)
 	place.Rating = details.Rating
-	place.Schedule = stringFrom(details.OpeningHours)
+	place.Schedule = stringFrom(details.OpeningHours, now.Weekday())
+	place.Hours = hoursFrom(details.OpeningHours)
+	if place.Hours != nil {
+		place.Hours.UTCOffset = details.UTCOffset
+	}
+	place.OpenNow = openNowFrom(details.OpeningHours)
 	place.Types = strings.Join(details.Types, ",")
 
 	return place, nil
 }
 
-func stringFrom(schedule *maps.OpeningHours) string {
+func reviewsFrom(reviews []maps.PlaceReview) *domain.ReviewsSummary {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	summary := &domain.ReviewsSummary{Count: len(reviews)}
+	var total int
+	for _, review := range reviews {
+		total += review.Rating
+		if summary.Excerpt == "" {
+			summary.Excerpt = excerptFrom(review.Text)
+		}
+	}
+	summary.Rating = float32(total) / float32(len(reviews))
+
+	return summary
+}
+
+func excerptFrom(text string) string {
+	const maxExcerpt = 200
+
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxExcerpt {
+		return string(runes)
+	}
+	return string(runes[:maxExcerpt]) + "…"
+}
+
+func photosFrom(photos []maps.Photo) []string {
+	var references []string
+	for _, photo := range photos {
+		references = append(references, photo.PhotoReference)
+	}
+	return references
+}
+
+func placeFrom(result maps.PlacesSearchResult, today time.Weekday) domain.Place {
+	place := domain.Place{}
+	place.ID = result.PlaceID
+	place.Address = result.Vicinity // Nearby Search has no formatted address, see PlaceDetails
+	place.Location = locationFrom(result.Geometry.Location)
+	place.Name = result.Name
+	place.PriceLevel = priceLevelFrom(result.PriceLevel)
+	place.Rating = result.Rating
+	place.Schedule = stringFrom(result.OpeningHours, today)
+	place.OpenNow = openNowFrom(result.OpeningHours)
+	place.Types = strings.Join(result.Types, ",")
+
+	return place
+}
+
+func locationFrom(latLng maps.LatLng) domain.Location {
+	return domain.Location{
+		Lat: latLng.Lat,
+		Lng: latLng.Lng,
+	}
+}
+
+func hoursFrom(schedule *maps.OpeningHours) *domain.OpeningHours {
+	if schedule == nil || len(schedule.Periods) == 0 {
+		return nil
+	}
+
+	hours := &domain.OpeningHours{}
+	for _, p := range schedule.Periods {
+		period := domain.Period{
+			Open: domain.DayTime{Day: p.Open.Day, Time: p.Open.Time},
+		}
+		// Places open around the clock only report an opening on Sunday at 0000
+		if p.Close.Time != "" {
+			period.Close = &domain.DayTime{Day: p.Close.Day, Time: p.Close.Time}
+		}
+		hours.Periods = append(hours.Periods, period)
+	}
+
+	return hours
+}
+
+func openNowFrom(schedule *maps.OpeningHours) *bool {
+	if schedule == nil {
+		return nil
+	}
+	return schedule.OpenNow
+}
+
+func stringFrom(schedule *maps.OpeningHours, today time.Weekday) string {
 	if schedule != nil {
 		for _, p := range schedule.Periods {
-			if time.Now().Weekday() == p.Open.Day {
+			if today == p.Open.Day {
 				return p.Open.Time
 			}
 		}
 	}
 	return ""
 }
+
+// priceLevelFrom reads Google's price level, which decodes a missing one as 0.
+// Restaurants aren't free, so 0 is taken as unknown
+func priceLevelFrom(level int) *int {
+	if level == 0 {
+		return nil
+	}
+	return domain.KnownPrice(level)
+}
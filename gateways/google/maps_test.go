@@ -0,0 +1,144 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"googlemaps.github.io/maps"
+
+	. "github.com/onsi/gomega"
+)
+
+// slowMaps stands in for the Google Maps API, answering after delay unless the
+// caller gives up first
+func slowMaps(delay time.Duration, cancelled chan<- string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			cancelled <- r.URL.Path
+		case <-time.After(delay):
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status": "OK", "results": [], "result": {"place_id": "abc"}}`))
+		}
+	}))
+}
+
+func newTestGateway(t *testing.T, baseURL string, options ...Option) GeoGateway {
+	client, err := maps.NewClient(maps.WithAPIKey("test-key"), maps.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewGoogleGateway(client, options...)
+}
+
+func TestPlaceDetailsIsCancelledWithTheRequest(t *testing.T) {
+	RegisterTestingT(t)
+	cancelled := make(chan string, 1)
+	server := slowMaps(time.Minute, cancelled)
+	defer server.Close()
+	gateway := newTestGateway(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := gateway.PlaceDetails(ctx, "abc")
+	Expect(err).To(HaveOccurred())
+	Eventually(cancelled).Should(Receive(Equal("/maps/api/place/details/json")), "upstream should see the client go away")
+}
+
+func TestEndpointTimeouts(t *testing.T) {
+	RegisterTestingT(t)
+	cancelled := make(chan string, 1)
+	server := slowMaps(200*time.Millisecond, cancelled)
+	defer server.Close()
+	gateway := newTestGateway(t, server.URL, WithTimeouts(map[Endpoint]time.Duration{
+		NearbyEndpoint:  50 * time.Millisecond,
+		DetailsEndpoint: time.Second,
+	}))
+
+	_, err := gateway.ListRestaurants(context.Background(), domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("deadline exceeded"))
+	Eventually(cancelled).Should(Receive(Equal("/maps/api/place/nearbysearch/json")))
+
+	place, err := gateway.PlaceDetails(context.Background(), "abc")
+	Expect(err).NotTo(HaveOccurred(), "details have time enough")
+	Expect(place.ID).To(Equal("abc"))
+}
+
+func TestPlaceDetailsAsksOnlyForTheFieldsItReads(t *testing.T) {
+	RegisterTestingT(t)
+	var fields string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = r.URL.Query().Get("fields")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "OK", "result": {"place_id": "abc", "name": "Tasca do Chico", "utc_offset": 60}}`))
+	}))
+	defer server.Close()
+	gateway := newTestGateway(t, server.URL)
+
+	place, err := gateway.PlaceDetails(context.Background(), "abc")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(place.Name).To(Equal("Tasca do Chico"))
+	Expect(strings.Split(fields, ",")).To(ConsistOf(
+		"place_id", "formatted_address", "geometry/location", "name", "formatted_phone_number", "website",
+		"reviews", "photos", "price_level", "rating", "opening_hours", "utc_offset", "types",
+	), "every other field is billed and thrown away")
+}
+
+func TestForward(t *testing.T) {
+	RegisterTestingT(t)
+	var address string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address = r.URL.Query().Get("address")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "OK", "results": [{
+			"formatted_address": "R. Augusta, 1100-053 Lisboa, Portugal",
+			"geometry": {
+				"location": {"lat": 38.7107, "lng": -9.1365},
+				"viewport": {"northeast": {"lat": 38.7152, "lng": -9.1365}, "southwest": {"lat": 38.7062, "lng": -9.1365}}
+			}
+		}]}`))
+	}))
+	defer server.Close()
+	gateway := newTestGateway(t, server.URL)
+
+	matches, err := gateway.Forward(context.Background(), "Rua Augusta, Lisboa")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(address).To(Equal("Rua Augusta, Lisboa"))
+	Expect(matches).To(HaveLen(1))
+	Expect(matches[0].Address).To(Equal("R. Augusta, 1100-053 Lisboa, Portugal"))
+	Expect(matches[0].Position.Location).To(Equal(domain.Location{Lat: 38.7107, Lng: -9.1365}))
+	Expect(matches[0].Position.Accuracy).To(BeNumerically("~", 500, 5), "half the viewport")
+}
+
+func TestGeolocateOnlyConsidersIPWithoutAccessPoints(t *testing.T) {
+	RegisterTestingT(t)
+	var considered []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ConsiderIP bool `json:"considerIp"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		considered = append(considered, request.ConsiderIP)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"location": {"lat": 38.7107, "lng": -9.1365}, "accuracy": 40}`))
+	}))
+	defer server.Close()
+	gateway := newTestGateway(t, server.URL)
+
+	_, err := gateway.Geolocate(context.Background(), []maps.WiFiAccessPoint{{MACAddress: "00:25:9c:cf:1c:ac"}, {MACAddress: "00:25:9c:cf:1c:ad"}})
+	Expect(err).NotTo(HaveOccurred())
+	_, err = gateway.Geolocate(context.Background(), nil)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(considered).To(Equal([]bool{false, true}), "an IP fix shouldn't pass for a Wi-Fi one")
+}
@@ -0,0 +1,93 @@
+package google
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"googlemaps.github.io/maps"
+)
+
+const (
+	// DefaultMaxPages Nearby Search never serves more than 3 pages of 20 results
+	DefaultMaxPages = 3
+	// DefaultPageDelay how long Google takes before a next_page_token can be used
+	DefaultPageDelay = 2 * time.Second
+)
+
+type nearbySearcher interface {
+	NearbySearch(ctx context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error)
+}
+
+// limitedSearcher applies the limits of the Nearby Search endpoint to every page requested
+type limitedSearcher struct {
+	client nearbySearcher
+	limits limits
+}
+
+func (l limitedSearcher) NearbySearch(ctx context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
+	ctx, cancel, err := l.limits.begin(ctx, NearbyEndpoint)
+	if err != nil {
+		return maps.PlacesSearchResponse{}, err
+	}
+	defer cancel()
+
+	return l.client.NearbySearch(ctx, r)
+}
+
+// Paginator follows the next page tokens of a Nearby Search
+type Paginator struct {
+	client    nearbySearcher
+	maxPages  int
+	pageDelay time.Duration
+}
+
+// NewPaginator returns a Paginator fetching at most maxPages pages, waiting pageDelay between them
+func NewPaginator(client nearbySearcher, maxPages int, pageDelay time.Duration) Paginator {
+	if maxPages < 1 {
+		maxPages = 1
+	}
+
+	return Paginator{
+		client:    client,
+		maxPages:  maxPages,
+		pageDelay: pageDelay,
+	}
+}
+
+// All returns the results of every page, up to the page cap. A failure after the
+// first page returns what was gathered so far along with the error
+func (p Paginator) All(ctx context.Context, searchRequest *maps.NearbySearchRequest) ([]maps.PlacesSearchResult, error) {
+	var results []maps.PlacesSearchResult
+	pageRequest := *searchRequest
+
+	for page := 1; ; page++ {
+		response, err := p.client.NearbySearch(ctx, &pageRequest)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, response.Results...)
+		log.Printf("Found %d results in page %d", len(response.Results), page)
+
+		if len(response.NextPageToken) == 0 || page >= p.maxPages {
+			return results, nil
+		}
+
+		if err := p.wait(ctx); err != nil {
+			return results, err
+		}
+		pageRequest = maps.NearbySearchRequest{PageToken: response.NextPageToken}
+	}
+}
+
+func (p Paginator) wait(ctx context.Context) error {
+	timer := time.NewTimer(p.pageDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"googlemaps.github.io/maps"
+
+	. "github.com/onsi/gomega"
+)
+
+type pagedSearch struct {
+	pages    []maps.PlacesSearchResponse
+	requests []maps.NearbySearchRequest
+	failAt   int
+}
+
+func (p *pagedSearch) NearbySearch(ctx context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
+	p.requests = append(p.requests, *r)
+	page := len(p.requests)
+	if page == p.failAt {
+		return maps.PlacesSearchResponse{}, errors.New("INVALID_REQUEST")
+	}
+	return p.pages[page-1], nil
+}
+
+func threePages() []maps.PlacesSearchResponse {
+	return []maps.PlacesSearchResponse{
+		{Results: []maps.PlacesSearchResult{{PlaceID: "1"}, {PlaceID: "2"}}, NextPageToken: "page-2"},
+		{Results: []maps.PlacesSearchResult{{PlaceID: "3"}}, NextPageToken: "page-3"},
+		{Results: []maps.PlacesSearchResult{{PlaceID: "4"}}},
+	}
+}
+
+func TestPaginatorAll(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		maxPages      int
+		failAt        int
+		expectedIDs   []string
+		expectedCalls int
+		expectError   bool
+	}{
+		{
+			desc:          "Follows every page",
+			maxPages:      DefaultMaxPages,
+			expectedIDs:   []string{"1", "2", "3", "4"},
+			expectedCalls: 3,
+		},
+		{
+			desc:          "Stops at the page cap",
+			maxPages:      2,
+			expectedIDs:   []string{"1", "2", "3"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "Keeps earlier pages when a later one fails",
+			maxPages:      DefaultMaxPages,
+			failAt:        2,
+			expectedIDs:   []string{"1", "2"},
+			expectedCalls: 2,
+			expectError:   true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			search := &pagedSearch{pages: threePages(), failAt: tC.failAt}
+
+			paginator := NewPaginator(search, tC.maxPages, time.Millisecond)
+			results, err := paginator.All(context.Background(), &maps.NearbySearchRequest{Radius: 500})
+			if tC.expectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			var ids []string
+			for _, result := range results {
+				ids = append(ids, result.PlaceID)
+			}
+			Expect(ids).To(Equal(tC.expectedIDs))
+			Expect(search.requests).To(HaveLen(tC.expectedCalls))
+			if tC.expectedCalls > 1 {
+				Expect(search.requests[1].PageToken).To(Equal("page-2"))
+			}
+		})
+	}
+}
+
+func TestPaginatorCancelledWait(t *testing.T) {
+	RegisterTestingT(t)
+	search := &pagedSearch{pages: threePages()}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	paginator := NewPaginator(search, DefaultMaxPages, time.Hour)
+	results, err := paginator.All(ctx, &maps.NearbySearchRequest{Radius: 500})
+
+	Expect(err).To(MatchError(context.DeadlineExceeded))
+	Expect(results).To(HaveLen(2), "first page should still be returned")
+	Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+}
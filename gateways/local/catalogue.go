@@ -0,0 +1,312 @@
+// Package local provides a gateway serving restaurants from an offline, file backed catalogue
+package local
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// Supported import/export formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var (
+	// ErrPlaceNotFound error sent when the catalogue has no place with the given ID
+	ErrPlaceNotFound error = domain.NewError(domain.NotFound, "place not found in catalogue")
+	// ErrUnknownFormat error sent when importing or exporting an unsupported format
+	ErrUnknownFormat = errors.New("unknown catalogue format")
+
+	csvHeader = []string{"id", "name", "lat", "lng", "address", "phone", "website", "rating", "price_level", "cuisine", "types", "schedule"}
+)
+
+// Catalogue a list of places persisted as JSON on disk
+type Catalogue struct {
+	path   string
+	mu     sync.RWMutex
+	places map[string]domain.Place
+}
+
+// Open loads the catalogue stored at path, an absent file is an empty catalogue
+func Open(path string) (*Catalogue, error) {
+	catalogue := &Catalogue{
+		path:   path,
+		places: make(map[string]domain.Place),
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return catalogue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := catalogue.Import(file, FormatJSON); err != nil {
+		return nil, err
+	}
+
+	return catalogue, nil
+}
+
+// FormatFrom guesses the catalogue format from a file name
+func FormatFrom(name string) string {
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// ListRestaurants ...
+func (c *Catalogue) ListRestaurants(ctx context.Context, searchRequest domain.SearchRequest) ([]domain.Place, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	origin := searchRequest.Origin()
+	var places []domain.Place
+	for _, place := range c.places {
+		if origin.DistanceTo(place.Location) <= float64(searchRequest.Distance) {
+			places = append(places, place)
+		}
+	}
+
+	sort.Slice(places, func(i, j int) bool {
+		return origin.DistanceTo(places[i].Location) < origin.DistanceTo(places[j].Location)
+	})
+
+	return places, nil
+}
+
+// PlaceDetails ...
+func (c *Catalogue) PlaceDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	place, ok := c.places[placeID]
+	if !ok {
+		return domain.Place{}, ErrPlaceNotFound
+	}
+
+	return place, nil
+}
+
+// Import adds or replaces places read in the given format, returning how many were read
+func (c *Catalogue) Import(r io.Reader, format string) (int, error) {
+	var places []domain.Place
+	var err error
+
+	switch format {
+	case FormatJSON:
+		err = json.NewDecoder(r).Decode(&places)
+	case FormatCSV:
+		places, err = readCSV(r)
+	default:
+		err = ErrUnknownFormat
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, place := range places {
+		if place.ID == "" {
+			place.ID = c.nextID()
+		}
+		c.places[place.ID] = place
+	}
+
+	return len(places), nil
+}
+
+// Export writes every place in the given format
+func (c *Catalogue) Export(w io.Writer, format string) error {
+	places := c.sorted()
+
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(places)
+	case FormatCSV:
+		return writeCSV(w, places)
+	}
+
+	return ErrUnknownFormat
+}
+
+// Save persists the catalogue to its path
+func (c *Catalogue) Save() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := c.Export(tmp, FormatJSON); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
+
+func (c *Catalogue) nextID() string {
+	for n := len(c.places) + 1; ; n++ {
+		id := fmt.Sprintf("local/%d", n)
+		if _, taken := c.places[id]; !taken {
+			return id
+		}
+	}
+}
+
+func (c *Catalogue) sorted() []domain.Place {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	places := make([]domain.Place, 0, len(c.places))
+	for _, place := range c.places {
+		places = append(places, place)
+	}
+	sort.Slice(places, func(i, j int) bool {
+		return places[i].ID < places[j].ID
+	})
+
+	return places
+}
+
+func readCSV(r io.Reader) ([]domain.Place, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"name", "lat", "lng"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv is missing the %q column", required)
+		}
+	}
+
+	places := make([]domain.Place, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		place, err := placeFrom(field)
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %s", line+2, err.Error())
+		}
+		places = append(places, place)
+	}
+
+	return places, nil
+}
+
+func placeFrom(field func(name string) string) (place domain.Place, err error) {
+	place.ID = field("id")
+	place.Name = field("name")
+	place.Address = field("address")
+	place.Phone = field("phone")
+	place.Website = field("website")
+	place.Cuisine = field("cuisine")
+	place.Types = field("types")
+	place.Schedule = field("schedule")
+	if place.Schedule != "" {
+		// the place is still worth having, just as if its hours were unknown
+		if place.Hours, err = domain.ParseOpeningHours(place.Schedule); err != nil {
+			log.Printf("Keeping %s without opening hours, could not read %q: %s", place.Name, place.Schedule, err.Error())
+		}
+	}
+
+	if place.Location.Lat, err = strconv.ParseFloat(field("lat"), 64); err != nil {
+		return place, err
+	}
+	if place.Location.Lng, err = strconv.ParseFloat(field("lng"), 64); err != nil {
+		return place, err
+	}
+	if rating := field("rating"); rating != "" {
+		value, err := strconv.ParseFloat(rating, 32)
+		if err != nil {
+			return place, err
+		}
+		place.Rating = float32(value)
+	}
+	if priceLevel := field("price_level"); priceLevel != "" {
+		level, err := strconv.Atoi(priceLevel)
+		if err != nil {
+			return place, err
+		}
+		place.PriceLevel = domain.KnownPrice(level)
+	}
+
+	return place, nil
+}
+
+func writeCSV(w io.Writer, places []domain.Place) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, place := range places {
+		err := writer.Write([]string{
+			place.ID,
+			place.Name,
+			strconv.FormatFloat(place.Location.Lat, 'f', -1, 64),
+			strconv.FormatFloat(place.Location.Lng, 'f', -1, 64),
+			place.Address,
+			place.Phone,
+			place.Website,
+			strconv.FormatFloat(float64(place.Rating), 'f', -1, 32),
+			priceLevelString(place.PriceLevel),
+			place.Cuisine,
+			place.Types,
+			place.Schedule,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// priceLevelString writes an unknown price level as an empty field
+func priceLevelString(level *int) string {
+	if level == nil {
+		return ""
+	}
+	return strconv.Itoa(*level)
+}
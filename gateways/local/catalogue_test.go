@@ -0,0 +1,124 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+
+	. "github.com/onsi/gomega"
+)
+
+// importedCatalogue returns a catalogue of the test places, along with the
+// function removing its directory
+func importedCatalogue(t *testing.T) (*Catalogue, func()) {
+	dir, err := ioutil.TempDir("", "catalogue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	catalogue, err := Open(filepath.Join(dir, "catalogue.json"))
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	file, err := os.Open("testdata/lunch_spots.csv")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := catalogue.Import(file, FormatCSV); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return catalogue, cleanup
+}
+
+func TestListRestaurants(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		distance uint
+		expected []string
+	}{
+		{
+			desc:     "Only places within walking distance",
+			distance: 500,
+			expected: []string{"Burger Baixa", "Tasca do Chico"},
+		},
+		{
+			desc:     "Far enough to reach Cascais",
+			distance: 30000,
+			expected: []string{"Burger Baixa", "Tasca do Chico", "Marisqueira de Cascais"},
+		},
+		{
+			desc:     "Nothing that close",
+			distance: 10,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			catalogue, cleanup := importedCatalogue(t)
+			defer cleanup()
+
+			places, err := catalogue.ListRestaurants(context.Background(), domain.SearchRequest{
+				Lat:      38.7098,
+				Lng:      -9.1362,
+				Distance: tC.distance,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			for _, place := range places {
+				names = append(names, place.Name)
+			}
+			Expect(names).To(Equal(tC.expected), "should be sorted by distance")
+		})
+	}
+}
+
+func TestSaveAndReopen(t *testing.T) {
+	RegisterTestingT(t)
+	catalogue, cleanup := importedCatalogue(t)
+	defer cleanup()
+
+	Expect(catalogue.Save()).To(Succeed())
+
+	reopened, err := Open(catalogue.path)
+	Expect(err).NotTo(HaveOccurred())
+
+	place, err := reopened.PlaceDetails(context.Background(), "local/1")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(place.Name).To(Equal("Tasca do Chico"))
+	Expect(place.PriceLevel).To(Equal(domain.KnownPrice(1)))
+
+	var exported bytes.Buffer
+	Expect(reopened.Export(&exported, FormatCSV)).To(Succeed())
+	Expect(exported.String()).To(HavePrefix("id,name,lat,lng,address,phone,website,rating,price_level,cuisine,types,schedule\nlocal/1,Tasca do Chico,38.7101521,-9.1370128"))
+
+	_, err = reopened.PlaceDetails(context.Background(), "local/42")
+	Expect(err).To(MatchError(ErrPlaceNotFound))
+}
+
+func TestReadCSVKeepsUnreadableSchedules(t *testing.T) {
+	RegisterTestingT(t)
+	places, err := readCSV(strings.NewReader("name,lat,lng,schedule\n" +
+		"Tasca do Chico,38.7101521,-9.1370128,PH off\n" +
+		"Burger Baixa,38.7098342,-9.1359812,Mo-Fr 12:00-15:00\n"))
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(places).To(HaveLen(2))
+	Expect(places[0].Schedule).To(Equal("PH off"))
+	Expect(places[0].Hours).To(BeNil(), "its hours are unknown")
+	Expect(places[1].Hours).NotTo(BeNil())
+}
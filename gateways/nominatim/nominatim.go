@@ -0,0 +1,202 @@
+// Package nominatim provides gateway logic for geocoding addresses and place
+// names with Nominatim, the OpenStreetMap geocoder, public or self-hosted
+package nominatim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"golang.org/x/time/rate"
+)
+
+// DefaultEndpoint the public Nominatim instance, meant for light use only
+const DefaultEndpoint = "https://nominatim.openstreetmap.org"
+
+// DefaultUserAgent identifies the application, as the Nominatim usage policy
+// requires. Deployments should name themselves instead
+const DefaultUserAgent = "where-to-eat"
+
+// MaxRate the most requests per second the public instance's usage policy allows
+const MaxRate = rate.Limit(1)
+
+// DefaultTimeout bounds a request when no client is given
+const DefaultTimeout = 10 * time.Second
+
+// limit matches asked for, enough to tell an ambiguous query
+const limit = 5
+
+// StatusError error sent when Nominatim answers with an unexpected HTTP status
+type StatusError struct {
+	Code int
+}
+
+func (s StatusError) Error() string {
+	return fmt.Sprintf("nominatim returned status %d", s.Code)
+}
+
+// StatusCode returns the HTTP status Nominatim answered with
+func (s StatusError) StatusCode() int {
+	return s.Code
+}
+
+// ErrorKind returns UpstreamUnavailable when Nominatim is overloaded or down
+func (s StatusError) ErrorKind() domain.ErrorKind {
+	switch s.Code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return domain.UpstreamUnavailable
+	}
+	return domain.Unknown
+}
+
+// Gateway ...
+type Gateway struct {
+	endpoint  string
+	client    *http.Client
+	limiter   *rate.Limiter
+	userAgent string
+	email     string
+}
+
+// Option configures a Gateway
+type Option func(*Gateway)
+
+// WithLimiter paces the requests with limiter, which may be shared so that
+// replacing the gateway doesn't reset it
+func WithLimiter(limiter *rate.Limiter) Option {
+	return func(g *Gateway) {
+		g.limiter = limiter
+	}
+}
+
+// WithContact identifies the deployment to the Nominatim operators by its
+// User-Agent and, when not empty, an email address
+func WithContact(userAgent, email string) Option {
+	return func(g *Gateway) {
+		if userAgent != "" {
+			g.userAgent = userAgent
+		}
+		g.email = email
+	}
+}
+
+// NewGateway returns a gateway querying the Nominatim instance at endpoint, at
+// most MaxRate times a second
+func NewGateway(endpoint string, client *http.Client, options ...Option) Gateway {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	gateway := Gateway{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		client:    client,
+		limiter:   rate.NewLimiter(MaxRate, 1),
+		userAgent: DefaultUserAgent,
+	}
+	for _, option := range options {
+		option(&gateway)
+	}
+
+	return gateway
+}
+
+type place struct {
+	Lat         string   `json:"lat"`
+	Lon         string   `json:"lon"`
+	DisplayName string   `json:"display_name"`
+	BoundingBox []string `json:"boundingbox"`
+}
+
+// Forward returns the places an address or place name refers to, best match first
+func (g *Gateway) Forward(ctx context.Context, query string) ([]domain.Match, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "jsonv2")
+	params.Set("limit", strconv.Itoa(limit))
+	if g.email != "" {
+		params.Set("email", g.email)
+	}
+
+	var places []place
+	if err := g.get(ctx, "/search?"+params.Encode(), &places); err != nil {
+		return nil, err
+	}
+
+	matches := make([]domain.Match, 0, len(places))
+	for _, p := range places {
+		match, err := matchFrom(p)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// Health checks the Nominatim instance answers its status page. It doesn't
+// wait on the limiter, which only paces searches
+func (g *Gateway) Health(ctx context.Context) error {
+	return g.fetch(ctx, "/status?format=json", nil)
+}
+
+// get fetches path once the limiter allows another request
+func (g *Gateway) get(ctx context.Context, path string, body interface{}) error {
+	if err := g.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return g.fetch(ctx, path, body)
+}
+
+func (g *Gateway) fetch(ctx context.Context, path string, body interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, g.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", g.userAgent)
+
+	resp, err := g.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return StatusError{Code: resp.StatusCode}
+	}
+
+	if body == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(body)
+}
+
+func matchFrom(p place) (domain.Match, error) {
+	location, err := domain.ParseLocation(p.Lat + "," + p.Lon)
+	if err != nil {
+		return domain.Match{}, err
+	}
+
+	match := domain.Match{
+		Address:  p.DisplayName,
+		Position: domain.Position{Location: location},
+	}
+
+	// the bounding box is south, north, west and east
+	if len(p.BoundingBox) == 4 {
+		southWest, swErr := domain.ParseLocation(p.BoundingBox[0] + "," + p.BoundingBox[2])
+		northEast, neErr := domain.ParseLocation(p.BoundingBox[1] + "," + p.BoundingBox[3])
+		if swErr == nil && neErr == nil {
+			match.Position.Accuracy = southWest.DistanceTo(northEast) / 2
+		}
+	}
+	return match, nil
+}
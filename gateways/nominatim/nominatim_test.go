@@ -0,0 +1,102 @@
+package nominatim
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"golang.org/x/time/rate"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestForward(t *testing.T) {
+	RegisterTestingT(t)
+	payload, err := ioutil.ReadFile("testdata/search.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	gateway := NewGateway(server.URL+"/", server.Client())
+	matches, err := gateway.Forward(context.Background(), "Rua Augusta, Lisboa")
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(requests).To(HaveLen(1))
+	Expect(requests[0].URL.Path).To(Equal("/search"))
+	Expect(requests[0].URL.Query().Get("q")).To(Equal("Rua Augusta, Lisboa"))
+	Expect(requests[0].URL.Query().Get("format")).To(Equal("jsonv2"))
+	Expect(requests[0].Header.Get("User-Agent")).To(Equal("where-to-eat"))
+
+	Expect(matches).To(HaveLen(3))
+	Expect(matches[0].Address).To(Equal("Rua Augusta, Baixa, Santa Maria Maior, Lisboa, 1100-053, Portugal"))
+	Expect(matches[0].Position.Location).To(Equal(domain.Location{Lat: 38.7107323, Lng: -9.1365212}))
+	Expect(matches[0].Position.Accuracy).To(BeNumerically("~", 305, 5), "half the bounding box diagonal")
+}
+
+func TestForwardUnavailable(t *testing.T) {
+	RegisterTestingT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	gateway := NewGateway(server.URL, server.Client())
+	_, err := gateway.Forward(context.Background(), "Rua Augusta, Lisboa")
+	Expect(err).To(Equal(StatusError{Code: http.StatusServiceUnavailable}))
+	Expect(domain.KindOf(err)).To(Equal(domain.UpstreamUnavailable))
+}
+
+func TestForwardIdentifiesAndPaces(t *testing.T) {
+	RegisterTestingT(t)
+	var requests []*http.Request
+	var arrivals []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		arrivals = append(arrivals, time.Now())
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	gateway := NewGateway(server.URL, server.Client(),
+		WithLimiter(rate.NewLimiter(rate.Every(100*time.Millisecond), 1)),
+		WithContact("lunch-bot/1.0", "ops@example.com"),
+	)
+	for i := 0; i < 2; i++ {
+		_, err := gateway.Forward(context.Background(), "Rua Augusta, Lisboa")
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(requests[0].Header.Get("User-Agent")).To(Equal("lunch-bot/1.0"))
+	Expect(requests[0].URL.Query().Get("email")).To(Equal("ops@example.com"))
+	Expect(arrivals[1].Sub(arrivals[0])).To(BeNumerically(">=", 90*time.Millisecond), "requests should be paced")
+	Expect(NewGateway("", nil).client.Timeout).To(Equal(DefaultTimeout))
+}
+
+func TestHealthLeavesTheLimiterToSearches(t *testing.T) {
+	RegisterTestingT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	gateway := NewGateway(server.URL, server.Client(), WithLimiter(rate.NewLimiter(rate.Every(time.Hour), 1)))
+	Expect(gateway.Health(context.Background())).To(Succeed())
+	Expect(gateway.Health(context.Background())).To(Succeed())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := gateway.Forward(ctx, "Rua Augusta, Lisboa")
+	Expect(err).NotTo(HaveOccurred(), "health checks shouldn't have spent the search's turn")
+}
@@ -0,0 +1,244 @@
+// Package osm provides gateway logic for searching restaurants on OpenStreetMap through the Overpass API
+package osm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// DefaultEndpoint the public Overpass API interpreter
+const DefaultEndpoint = "https://overpass-api.de/api/interpreter"
+
+const amenities = "restaurant|cafe|fast_food"
+
+// ErrInvalidPlaceID error sent when a place ID is not in the "<type>/<id>" form
+var ErrInvalidPlaceID error = domain.NewError(domain.Validation, "invalid OpenStreetMap place id")
+
+// StatusError error sent when Overpass answers with an unexpected HTTP status
+type StatusError struct {
+	Code int
+}
+
+func (s StatusError) Error() string {
+	return fmt.Sprintf("overpass returned status %d", s.Code)
+}
+
+// StatusCode returns the HTTP status Overpass answered with
+func (s StatusError) StatusCode() int {
+	return s.Code
+}
+
+// ErrorKind returns UpstreamUnavailable when Overpass is overloaded or down
+func (s StatusError) ErrorKind() domain.ErrorKind {
+	switch s.Code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return domain.UpstreamUnavailable
+	}
+	return domain.Unknown
+}
+
+// OverpassGateway ...
+type OverpassGateway struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewOverpassGateway returns a gateway querying the given Overpass interpreter endpoint
+func NewOverpassGateway(endpoint string, client *http.Client) OverpassGateway {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return OverpassGateway{
+		endpoint: endpoint,
+		client:   client,
+	}
+}
+
+type center struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type element struct {
+	Type   string            `json:"type"`
+	ID     int64             `json:"id"`
+	Lat    float64           `json:"lat"`
+	Lon    float64           `json:"lon"`
+	Center *center           `json:"center"`
+	Tags   map[string]string `json:"tags"`
+}
+
+type response struct {
+	Elements []element `json:"elements"`
+}
+
+// ListRestaurants ...
+func (g *OverpassGateway) ListRestaurants(ctx context.Context, searchRequest domain.SearchRequest) ([]domain.Place, error) {
+	query := restaurantsQuery(searchRequest)
+
+	elements, err := g.interpret(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	places := make([]domain.Place, 0, len(elements))
+	for _, e := range elements {
+		if e.Tags["name"] == "" {
+			continue
+		}
+		places = append(places, placeFrom(e))
+	}
+
+	return places, nil
+}
+
+// PlaceDetails ...
+func (g *OverpassGateway) PlaceDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	query, err := detailsQuery(placeID)
+	if err != nil {
+		return domain.Place{}, err
+	}
+
+	elements, err := g.interpret(ctx, query)
+	if err != nil {
+		return domain.Place{}, err
+	}
+
+	if len(elements) == 0 {
+		return domain.Place{}, domain.NewError(domain.NotFound, fmt.Sprintf("place %s not found", placeID))
+	}
+
+	return placeFrom(elements[0]), nil
+}
+
+// Health checks the Overpass instance answers its status page
+func (g *OverpassGateway) Health(ctx context.Context) error {
+	statusURL := strings.TrimSuffix(g.endpoint, "/interpreter") + "/status"
+
+	req, err := http.NewRequest(http.MethodGet, statusURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("overpass status returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (g *OverpassGateway) interpret(ctx context.Context, query string) ([]element, error) {
+	form := url.Values{}
+	form.Set("data", query)
+
+	req, err := http.NewRequest(http.MethodPost, g.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, StatusError{Code: resp.StatusCode}
+	}
+
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	return body.Elements, nil
+}
+
+func restaurantsQuery(searchRequest domain.SearchRequest) string {
+	around := fmt.Sprintf(
+		"(around:%d,%s,%s)",
+		searchRequest.Distance,
+		strconv.FormatFloat(searchRequest.Lat, 'f', -1, 64),
+		strconv.FormatFloat(searchRequest.Lng, 'f', -1, 64),
+	)
+	filter := fmt.Sprintf(`["amenity"~"^(%s)$"]`, amenities)
+
+	return "[out:json][timeout:25];(" +
+		"node" + filter + around + ";" +
+		"way" + filter + around + ";" +
+		");out center tags;"
+}
+
+func detailsQuery(placeID string) (string, error) {
+	parts := strings.Split(placeID, "/")
+	if len(parts) != 2 || (parts[0] != "node" && parts[0] != "way") {
+		return "", ErrInvalidPlaceID
+	}
+
+	if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return "", ErrInvalidPlaceID
+	}
+
+	return fmt.Sprintf("[out:json][timeout:25];%s(%s);out center tags;", parts[0], parts[1]), nil
+}
+
+func placeFrom(e element) domain.Place {
+	place := domain.Place{}
+	place.ID = fmt.Sprintf("%s/%d", e.Type, e.ID)
+	place.Name = e.Tags["name"]
+	place.Address = addressFrom(e.Tags)
+	place.Location = domain.Location{Lat: e.Lat, Lng: e.Lon}
+	if e.Center != nil {
+		place.Location = domain.Location{Lat: e.Center.Lat, Lng: e.Center.Lon}
+	}
+	place.Phone = firstTag(e.Tags, "phone", "contact:phone")
+	place.Website = firstTag(e.Tags, "website", "contact:website")
+	place.Schedule = e.Tags["opening_hours"]
+	if hours, err := domain.ParseOpeningHours(place.Schedule); err == nil && place.Schedule != "" {
+		place.Hours = hours
+	}
+	place.Cuisine = e.Tags["cuisine"]
+	place.Types = e.Tags["amenity"]
+
+	return place
+}
+
+func addressFrom(tags map[string]string) string {
+	street := strings.TrimSpace(tags["addr:street"] + " " + tags["addr:housenumber"])
+	locality := strings.TrimSpace(tags["addr:postcode"] + " " + tags["addr:city"])
+
+	var parts []string
+	for _, part := range []string{street, locality} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func firstTag(tags map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := tags[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package osm
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+
+	. "github.com/onsi/gomega"
+)
+
+func overpassStandIn(t *testing.T, fixture string, queries *[]string) *httptest.Server {
+	payload, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.FormValue("data"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(payload)
+	}))
+}
+
+func TestListRestaurants(t *testing.T) {
+	RegisterTestingT(t)
+
+	var queries []string
+	server := overpassStandIn(t, "testdata/restaurants.json", &queries)
+	defer server.Close()
+
+	gateway := NewOverpassGateway(server.URL, server.Client())
+	places, err := gateway.ListRestaurants(context.Background(), domain.SearchRequest{
+		Lat:      38.7107,
+		Lng:      -9.1365,
+		Distance: 500,
+	})
+
+	Expect(err).NotTo(HaveOccurred())
+	Expect(queries).To(HaveLen(1))
+	Expect(queries[0]).To(ContainSubstring(`node["amenity"~"^(restaurant|cafe|fast_food)$"](around:500,38.7107,-9.1365)`))
+	Expect(queries[0]).To(ContainSubstring(`way["amenity"~"^(restaurant|cafe|fast_food)$"](around:500,38.7107,-9.1365)`))
+
+	Expect(places).To(HaveLen(2), "unnamed elements should be skipped")
+
+	hours := places[0].Hours
+	Expect(hours).NotTo(BeNil())
+	Expect(hours.IsOpenAt(time.Date(2019, 3, 11, 13, 0, 0, 0, time.UTC))).To(BeTrue(), "open for Monday lunch")
+	Expect(hours.IsOpenAt(time.Date(2019, 3, 10, 13, 0, 0, 0, time.UTC))).To(BeFalse(), "closed on Sunday")
+
+	places[0].Hours = nil
+	Expect(places[0]).To(Equal(domain.Place{
+		ID:       "node/2515417343",
+		Address:  "Rua Augusta 96, 1100-053 Lisboa",
+		Location: domain.Location{Lat: 38.7101521, Lng: -9.1370128},
+		Name:     "Tasca do Chico",
+		Phone:    "+351 21 343 1030",
+		Schedule: "Mo-Sa 12:00-15:00,19:00-23:00",
+		Cuisine:  "portuguese",
+		Types:    "restaurant",
+	}))
+	Expect(places[1].ID).To(Equal("way/157340012"))
+	Expect(places[1].Location).To(Equal(domain.Location{Lat: 38.7098342, Lng: -9.1359812}))
+	Expect(places[1].Phone).To(Equal("+351 21 000 0000"))
+}
+
+func TestPlaceDetails(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		placeID       string
+		expectedQuery string
+		expectError   bool
+	}{
+		{
+			desc:          "Details for a node",
+			placeID:       "node/2515417343",
+			expectedQuery: "[out:json][timeout:25];node(2515417343);out center tags;",
+		},
+		{
+			desc:        "Google style place id",
+			placeID:     "ChIJ0X31pIK3voARo3mz1ebVzDo",
+			expectError: true,
+		},
+		{
+			desc:        "Unsupported element type",
+			placeID:     "relation/42",
+			expectError: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			var queries []string
+			server := overpassStandIn(t, "testdata/restaurants.json", &queries)
+			defer server.Close()
+
+			gateway := NewOverpassGateway(server.URL, server.Client())
+			place, err := gateway.PlaceDetails(context.Background(), tC.placeID)
+			if tC.expectError {
+				Expect(err).To(MatchError(ErrInvalidPlaceID))
+				Expect(queries).To(BeEmpty())
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queries).To(Equal([]string{tC.expectedQuery}))
+			Expect(place.Name).To(Equal("Tasca do Chico"))
+		})
+	}
+}
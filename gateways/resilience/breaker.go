@@ -0,0 +1,123 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// ErrCircuitOpen error sent without calling upstream while it is considered down
+var ErrCircuitOpen error = domain.NewError(domain.UpstreamUnavailable, "circuit open, upstream is unavailable")
+
+// State of a circuit breaker
+type State int
+
+// Breaker states
+const (
+	// Closed calls go through
+	Closed State = iota
+	// Open calls fail fast until the cooldown is over
+	Open
+	// HalfOpen a single call probes whether upstream recovered
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// Breaker opens after threshold consecutive failures, failing fast for
+// cooldown before letting a probe through
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreaker returns a closed Breaker
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenAfterCooldown()
+	return b.state
+}
+
+// Allow returns ErrCircuitOpen when the call must not be made, otherwise the
+// outcome of the call has to be recorded with Success or Failure
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenAfterCooldown()
+	switch b.state {
+	case Open:
+		return ErrCircuitOpen
+	case HalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records upstream answered, closing the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state, b.failures, b.probing = Closed, 0, false
+}
+
+// Release records a call ended without telling anything about upstream, such
+// as one its caller gave up on, letting another call probe in its place
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Failure records upstream being unavailable, opening the breaker once the
+// threshold is reached or when a probe fails
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state, b.openedAt, b.probing = Open, b.now(), false
+	}
+}
+
+func (b *Breaker) halfOpenAfterCooldown() {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = HalfOpen
+	}
+}
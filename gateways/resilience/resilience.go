@@ -0,0 +1,107 @@
+package resilience
+
+import (
+	"context"
+	"log"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"github.com/romeufcrosa/where-to-eat/domain/services"
+	maps "googlemaps.github.io/maps"
+)
+
+// PlacesSource guards a places source, answering from fallback when it is unavailable
+type PlacesSource struct {
+	source   services.PlacesSource
+	guard    *Guard
+	fallback services.PlacesSource
+}
+
+// NewPlacesSource returns source guarded by guard, fallback may be nil
+func NewPlacesSource(source services.PlacesSource, guard *Guard, fallback services.PlacesSource) PlacesSource {
+	return PlacesSource{
+		source:   source,
+		guard:    guard,
+		fallback: fallback,
+	}
+}
+
+// ListRestaurants ...
+func (p PlacesSource) ListRestaurants(ctx context.Context, req domain.SearchRequest) ([]domain.Place, error) {
+	var places []domain.Place
+	err := p.guard.Do(ctx, func(ctx context.Context) (err error) {
+		places, err = p.source.ListRestaurants(ctx, req)
+		return err
+	})
+	if p.shouldFallBack(err) {
+		log.Printf("Searching the fallback places source, reason: %s", err.Error())
+		return p.fallback.ListRestaurants(ctx, req)
+	}
+
+	return places, err
+}
+
+// PlaceDetails ...
+func (p PlacesSource) PlaceDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	var place domain.Place
+	err := p.guard.Do(ctx, func(ctx context.Context) (err error) {
+		place, err = p.source.PlaceDetails(ctx, placeID)
+		return err
+	})
+
+	// the fallback doesn't know the places of another source, no point asking it
+	return place, err
+}
+
+func (p PlacesSource) shouldFallBack(err error) bool {
+	return p.fallback != nil && domain.KindOf(err) == domain.UpstreamUnavailable
+}
+
+// GeoLocator guards a geo locator
+type GeoLocator struct {
+	geo   services.GeoLocator
+	guard *Guard
+}
+
+// NewGeoLocator returns geo guarded by guard
+func NewGeoLocator(geo services.GeoLocator, guard *Guard) GeoLocator {
+	return GeoLocator{
+		geo:   geo,
+		guard: guard,
+	}
+}
+
+// Geolocate ...
+func (g GeoLocator) Geolocate(ctx context.Context, accessPoints []maps.WiFiAccessPoint) (*maps.GeolocationResult, error) {
+	var result *maps.GeolocationResult
+	err := g.guard.Do(ctx, func(ctx context.Context) (err error) {
+		result, err = g.geo.Geolocate(ctx, accessPoints)
+		return err
+	})
+
+	return result, err
+}
+
+// Geocoder a geocoder whose calls are retried and cut off by a circuit breaker
+type Geocoder struct {
+	geocoder services.Geocoder
+	guard    *Guard
+}
+
+// NewGeocoder returns geocoder guarded by guard
+func NewGeocoder(geocoder services.Geocoder, guard *Guard) Geocoder {
+	return Geocoder{
+		geocoder: geocoder,
+		guard:    guard,
+	}
+}
+
+// Forward ...
+func (g Geocoder) Forward(ctx context.Context, query string) ([]domain.Match, error) {
+	var matches []domain.Match
+	err := g.guard.Do(ctx, func(ctx context.Context) (err error) {
+		matches, err = g.geocoder.Forward(ctx, query)
+		return err
+	})
+
+	return matches, err
+}
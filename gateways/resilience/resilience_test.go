@@ -0,0 +1,171 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	mocks "github.com/romeufcrosa/where-to-eat/tests/mocks/domain/services"
+
+	. "github.com/onsi/gomega"
+)
+
+type statusError int
+
+func (s statusError) Error() string   { return "unexpected status" }
+func (s statusError) StatusCode() int { return int(s) }
+
+func TestTransient(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{desc: "Success", err: nil, expected: false},
+		{desc: "Service unavailable", err: statusError(503), expected: true},
+		{desc: "Too many requests", err: statusError(429), expected: true},
+		{desc: "Bad request", err: statusError(400), expected: false},
+		{desc: "Google unknown error", err: errors.New("maps: UNKNOWN_ERROR - "), expected: true},
+		{desc: "Google request denied", err: errors.New("maps: REQUEST_DENIED - bad key"), expected: false},
+		{desc: "Connection refused", err: &url.Error{Op: "Post", URL: "http://overpass", Err: errors.New("connection refused")}, expected: true},
+		{desc: "Cancelled", err: &url.Error{Op: "Post", URL: "http://overpass", Err: context.Canceled}, expected: false},
+		{desc: "Quota exceeded", err: domain.ErrQuotaExceeded, expected: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			Expect(Transient(tC.err)).To(Equal(tC.expected))
+		})
+	}
+}
+
+func newTestGuard(policy Policy) (*Guard, *[]time.Duration) {
+	var slept []time.Duration
+	guard := NewGuard(policy)
+	guard.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	return guard, &slept
+}
+
+func TestGuardRetries(t *testing.T) {
+	RegisterTestingT(t)
+	guard, slept := newTestGuard(Policy{Attempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 150 * time.Millisecond, FailureThreshold: 5})
+
+	calls := 0
+	err := guard.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return statusError(502)
+		}
+		return nil
+	})
+
+	Expect(err).NotTo(HaveOccurred())
+	Expect(calls).To(Equal(3))
+	Expect(*slept).To(HaveLen(2))
+	Expect((*slept)[0]).To(BeNumerically("<=", 100*time.Millisecond))
+	Expect((*slept)[1]).To(BeNumerically("<=", 150*time.Millisecond), "backoff should be capped")
+
+	calls = 0
+	err = guard.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return statusError(400)
+	})
+	Expect(err).To(Equal(statusError(400)))
+	Expect(calls).To(Equal(1), "requests upstream rejected should not be retried")
+}
+
+func TestGuardOpensCircuit(t *testing.T) {
+	RegisterTestingT(t)
+	guard, _ := newTestGuard(Policy{Attempts: 2, FailureThreshold: 3, Cooldown: time.Minute})
+	now := time.Date(2019, 3, 15, 12, 0, 0, 0, time.UTC)
+	guard.breaker.now = func() time.Time { return now }
+
+	calls := 0
+	down := func(ctx context.Context) error {
+		calls++
+		return statusError(503)
+	}
+
+	err := guard.Do(context.Background(), down)
+	Expect(domain.KindOf(err)).To(Equal(domain.UpstreamUnavailable))
+	Expect(err.(*domain.KindError).Err).To(Equal(statusError(503)))
+	Expect(guard.Do(context.Background(), down)).To(Equal(ErrCircuitOpen))
+	Expect(calls).To(Equal(3))
+	Expect(guard.Breaker().State()).To(Equal(Open))
+
+	Expect(guard.Do(context.Background(), down)).To(Equal(ErrCircuitOpen))
+	Expect(calls).To(Equal(3), "an open circuit should fail fast")
+
+	now = now.Add(time.Minute)
+	Expect(guard.Breaker().State()).To(Equal(HalfOpen))
+	Expect(guard.Do(context.Background(), func(ctx context.Context) error { return nil })).To(Succeed())
+	Expect(guard.Breaker().State()).To(Equal(Closed), "a successful probe should close the circuit")
+}
+
+func TestGuardIgnoresCallsTheCallerGaveUpOn(t *testing.T) {
+	RegisterTestingT(t)
+	guard, slept := newTestGuard(Policy{Attempts: 3, FailureThreshold: 1, Cooldown: time.Minute})
+	now := time.Date(2019, 3, 15, 12, 0, 0, 0, time.UTC)
+	guard.breaker.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	calls := 0
+	timedOut := &url.Error{Op: "Get", URL: "http://nominatim", Err: context.DeadlineExceeded}
+	err := guard.Do(ctx, func(ctx context.Context) error {
+		calls++
+		return timedOut
+	})
+	Expect(err).To(Equal(timedOut))
+	Expect(domain.KindOf(err)).NotTo(Equal(domain.UpstreamUnavailable), "the fallback would be searched out of time")
+	Expect(calls).To(Equal(1), "there is no time left to retry")
+	Expect(*slept).To(BeEmpty())
+	Expect(guard.Breaker().State()).To(Equal(Closed), "the caller's deadline says nothing of upstream")
+
+	Expect(guard.Do(context.Background(), func(ctx context.Context) error { return statusError(503) })).NotTo(Succeed())
+	now = now.Add(time.Minute)
+	Expect(guard.Breaker().State()).To(Equal(HalfOpen))
+
+	probe, stop := context.WithCancel(context.Background())
+	err = guard.Do(probe, func(ctx context.Context) error {
+		stop()
+		return &url.Error{Op: "Get", URL: "http://nominatim", Err: context.Canceled}
+	})
+	Expect(err).To(HaveOccurred())
+	Expect(guard.Breaker().State()).To(Equal(HalfOpen), "a cancelled probe shouldn't close the circuit")
+	Expect(guard.Do(context.Background(), func(ctx context.Context) error { return nil })).To(Succeed(), "another call should be let through to probe")
+	Expect(guard.Breaker().State()).To(Equal(Closed))
+}
+
+func TestPlacesSourceFallsBack(t *testing.T) {
+	RegisterTestingT(t)
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500}
+	guard, _ := newTestGuard(Policy{Attempts: 2, FailureThreshold: 2, Cooldown: time.Minute})
+
+	google := &mocks.PlacesSource{}
+	google.On("ListRestaurants", mock.Anything, request).Return(nil, errors.New("maps: UNKNOWN_ERROR - "))
+	offline := &mocks.PlacesSource{}
+	offline.On("ListRestaurants", mock.Anything, request).Return([]domain.Place{{ID: "local/1"}}, nil)
+
+	source := NewPlacesSource(google, guard, offline)
+	for i := 0; i < 2; i++ {
+		places, err := source.ListRestaurants(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(places).To(ConsistOf(domain.Place{ID: "local/1"}))
+	}
+
+	google.AssertNumberOfCalls(t, "ListRestaurants", 2)
+	Expect(guard.Breaker().State()).To(Equal(Open))
+
+	_, err := NewPlacesSource(google, guard, nil).ListRestaurants(context.Background(), request)
+	Expect(err).To(Equal(ErrCircuitOpen), "without a fallback the caller should know upstream is down")
+}
@@ -0,0 +1,165 @@
+// Package resilience guards gateway calls with retries and a circuit breaker,
+// falling back to another places source when upstream is unavailable
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// Policy how failed calls are retried and when upstream is considered down
+type Policy struct {
+	// Attempts calls made at most, the first one included
+	Attempts int
+	// BaseDelay the backoff before the first retry, doubled on every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff
+	MaxDelay time.Duration
+	// FailureThreshold consecutive failures opening the circuit
+	FailureThreshold int
+	// Cooldown how long the circuit stays open before probing upstream
+	Cooldown time.Duration
+}
+
+// Guard retries transient failures with jittered exponential backoff behind a
+// circuit breaker
+type Guard struct {
+	policy  Policy
+	breaker *Breaker
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewGuard returns a Guard applying policy
+func NewGuard(policy Policy) *Guard {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+
+	return &Guard{
+		policy:  policy,
+		breaker: NewBreaker(policy.FailureThreshold, policy.Cooldown),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		sleep:   sleep,
+	}
+}
+
+// Policy returns the policy the guard applies
+func (g *Guard) Policy() Policy {
+	return g.policy
+}
+
+// Breaker returns the circuit breaker of the guard
+func (g *Guard) Breaker() *Breaker {
+	return g.breaker
+}
+
+// Do calls call until it succeeds, fails with an error not worth retrying or
+// runs out of attempts, transient failures being reported as UpstreamUnavailable.
+// It returns ErrCircuitOpen without calling when upstream is considered down.
+// Once ctx is done it stops, the breaker not counting the call either way
+func (g *Guard) Do(ctx context.Context, call func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < g.policy.Attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := g.sleep(ctx, g.backoff(attempt)); sleepErr != nil {
+				return sleepErr
+			}
+		}
+
+		if allowErr := g.breaker.Allow(); allowErr != nil {
+			return allowErr
+		}
+
+		err = call(ctx)
+		if ctx.Err() != nil {
+			// the caller gave up or ran out of time, upstream may be fine
+			g.breaker.Release()
+			return err
+		}
+		if !Transient(err) {
+			// upstream answered, even if only to say the request was wrong
+			g.breaker.Success()
+			return err
+		}
+		g.breaker.Failure()
+	}
+
+	return domain.WrapError(domain.UpstreamUnavailable, err)
+}
+
+// backoff returns a random delay up to BaseDelay doubled for every retry
+// already made, so clients failing together don't retry together
+func (g *Guard) backoff(attempt int) time.Duration {
+	ceiling := g.policy.BaseDelay << uint(attempt-1)
+	if ceiling <= 0 || (g.policy.MaxDelay > 0 && ceiling > g.policy.MaxDelay) {
+		ceiling = g.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return time.Duration(g.rnd.Int63n(int64(ceiling) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// statusCoder implemented by errors carrying the HTTP status upstream answered
+type statusCoder interface {
+	StatusCode() int
+}
+
+// transientStatuses Google API statuses meaning the call may succeed later
+var transientStatuses = []string{"maps: UNKNOWN_ERROR", "maps: OVER_QUERY_LIMIT"}
+
+// Transient returns whether err means upstream is unavailable for now, so the
+// call is worth retrying. Cancelled calls and spent quotas are not
+func Transient(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok && urlErr.Err == context.Canceled {
+		return false
+	}
+	if err == nil || err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+
+	if coder, ok := err.(statusCoder); ok {
+		switch coder.StatusCode() {
+		case 429, 500, 502, 503, 504:
+			return true
+		}
+		return false
+	}
+
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+
+	for _, status := range transientStatuses {
+		if strings.HasPrefix(err.Error(), status) {
+			return true
+		}
+	}
+	return false
+}
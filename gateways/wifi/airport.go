@@ -0,0 +1,37 @@
+package wifi
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// airportRow a row of airport -s, SSIDs being right aligned and possibly holding
+// spaces, channels possibly followed by the secondary one such as 36,+1
+var airportRow = regexp.MustCompile(`^\s*(.*?)\s+([0-9a-fA-F]{2}(?::[0-9a-fA-F]{2}){5})\s+(-\d+)\s+(\d+)`)
+
+// parseAirport returns the access points listed by airport -s
+func parseAirport(out string) []domain.AccessPoint {
+	var accessPoints []domain.AccessPoint
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		match := airportRow.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		rssi, _ := strconv.Atoi(match[3])
+		channel, _ := strconv.Atoi(match[4])
+
+		accessPoints = append(accessPoints, domain.AccessPoint{
+			BSSID:          strings.ToLower(match[2]),
+			SSID:           match[1],
+			Channel:        channel,
+			Frequency:      frequencyOf(channel),
+			SignalStrength: rssi,
+		})
+	}
+	return accessPoints
+}
@@ -0,0 +1,74 @@
+package wifi
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// parseIwInterfaces returns the interfaces listed by iw dev
+func parseIwInterfaces(out string) []string {
+	var interfaces []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "Interface" {
+			interfaces = append(interfaces, fields[1])
+		}
+	}
+	return interfaces
+}
+
+// parseIwScan returns the access points reported by iw dev <if> scan, the
+// channel worked out from the frequency
+func parseIwScan(out string) []domain.AccessPoint {
+	var accessPoints []domain.AccessPoint
+	var current *domain.AccessPoint
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "BSS ") && strings.Contains(line, "(on "):
+			accessPoints = append(accessPoints, domain.AccessPoint{
+				BSSID: strings.ToLower(line[len("BSS "):strings.Index(line, "(")]),
+			})
+			current = &accessPoints[len(accessPoints)-1]
+		case current == nil:
+		case strings.HasPrefix(line, "freq:"):
+			freq, err := strconv.ParseFloat(valueOf(line), 64)
+			if err == nil {
+				current.Frequency = int(freq)
+				current.Channel = channelOf(current.Frequency)
+			}
+		case strings.HasPrefix(line, "signal:"):
+			signal, err := strconv.ParseFloat(strings.TrimSuffix(valueOf(line), " dBm"), 64)
+			if err == nil {
+				current.SignalStrength = int(signal)
+			}
+		case strings.HasPrefix(line, "SSID:"):
+			current.SSID = valueOf(line)
+		case strings.HasPrefix(line, "last seen:") && strings.HasSuffix(line, " ms ago"):
+			ms, err := strconv.Atoi(strings.TrimSuffix(valueOf(line), " ms ago"))
+			if err == nil {
+				current.Age = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	var known []domain.AccessPoint
+	for _, accessPoint := range accessPoints {
+		if accessPoint.Channel > 0 {
+			known = append(known, accessPoint)
+		}
+	}
+	return known
+}
+
+// valueOf returns what follows the first colon of a line
+func valueOf(line string) string {
+	return strings.TrimSpace(line[strings.Index(line, ":")+1:])
+}
@@ -0,0 +1,50 @@
+package wifi
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// parseNetsh returns the access points listed by netsh wlan show networks
+// mode=bssid, each BSSID block following the SSID it broadcasts
+func parseNetsh(out string) []domain.AccessPoint {
+	var accessPoints []domain.AccessPoint
+	var current *domain.AccessPoint
+	var ssid string
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.Contains(line, ":") {
+			continue
+		}
+		key := strings.TrimSpace(line[:strings.Index(line, ":")])
+
+		switch {
+		case strings.HasPrefix(key, "SSID "):
+			ssid, current = valueOf(line), nil
+		case strings.HasPrefix(key, "BSSID "):
+			accessPoints = append(accessPoints, domain.AccessPoint{
+				BSSID: strings.ToLower(valueOf(line)),
+				SSID:  ssid,
+			})
+			current = &accessPoints[len(accessPoints)-1]
+		case current == nil:
+		case key == "Signal":
+			quality, err := strconv.Atoi(strings.TrimSuffix(valueOf(line), "%"))
+			if err == nil {
+				current.SignalStrength = dBmOf(quality)
+			}
+		case key == "Channel":
+			channel, err := strconv.Atoi(valueOf(line))
+			if err == nil {
+				current.Channel = channel
+				current.Frequency = frequencyOf(channel)
+			}
+		}
+	}
+	return accessPoints
+}
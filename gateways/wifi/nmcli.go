@@ -0,0 +1,58 @@
+package wifi
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+var nmcliArgs = []string{"-t", "-f", "BSSID,SSID,CHAN,FREQ,SIGNAL", "dev", "wifi", "list"}
+
+// parseNmcli returns the access points listed by nmcli -t -f BSSID,SSID,CHAN,FREQ,SIGNAL,
+// whose colons within fields are escaped
+func parseNmcli(out string) []domain.AccessPoint {
+	var accessPoints []domain.AccessPoint
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := splitTerse(scanner.Text())
+		if len(fields) < 5 || len(fields[0]) != len("00:00:00:00:00:00") {
+			continue
+		}
+		channel, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		freq, _ := strconv.Atoi(strings.TrimSuffix(fields[3], " MHz"))
+		quality, _ := strconv.Atoi(fields[4])
+
+		accessPoints = append(accessPoints, domain.AccessPoint{
+			BSSID:          strings.ToLower(fields[0]),
+			SSID:           fields[1],
+			Channel:        channel,
+			Frequency:      freq,
+			SignalStrength: dBmOf(quality),
+		})
+	}
+	return accessPoints
+}
+
+// splitTerse splits a line of nmcli terse output on the colons not escaped
+func splitTerse(line string) []string {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			field.WriteByte(line[i])
+		case line[i] == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+	return append(fields, field.String())
+}
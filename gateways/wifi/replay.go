@@ -0,0 +1,42 @@
+package wifi
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// Replay a Scanner answering with a scan captured earlier, so locating can be
+// tried without a radio
+type Replay struct {
+	accessPoints []domain.AccessPoint
+}
+
+// NewReplay returns a Replay of the scan saved at path in the given format
+func NewReplay(path string, format Format) (Replay, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Replay{}, err
+	}
+
+	accessPoints, err := Parse(format, string(data))
+	if err != nil {
+		return Replay{}, err
+	}
+	return Replay{accessPoints: accessPoints}, nil
+}
+
+// Scan returns the access points captured
+func (r Replay) Scan(ctx context.Context) ([]domain.AccessPoint, error) {
+	return append([]domain.AccessPoint(nil), r.accessPoints...), nil
+}
+
+func parseJSON(out string) ([]domain.AccessPoint, error) {
+	var accessPoints []domain.AccessPoint
+	if err := json.Unmarshal([]byte(out), &accessPoints); err != nil {
+		return nil, err
+	}
+	return accessPoints, nil
+}
@@ -0,0 +1,23 @@
+package wifi
+
+import (
+	"context"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// darwin scans with the airport utility
+type darwin struct{}
+
+// NewScanner returns the Scanner of this system
+func NewScanner() Scanner {
+	return darwin{}
+}
+
+func (darwin) Scan(ctx context.Context) ([]domain.AccessPoint, error) {
+	out, err := run(ctx, "airport", "-s")
+	if err != nil {
+		return nil, err
+	}
+	return parseAirport(out), nil
+}
@@ -0,0 +1,42 @@
+package wifi
+
+import (
+	"context"
+	"log"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// linux scans with NetworkManager, which doesn't need root, falling back to
+// iw on every wireless interface
+type linux struct{}
+
+// NewScanner returns the Scanner of this system
+func NewScanner() Scanner {
+	return linux{}
+}
+
+func (linux) Scan(ctx context.Context) ([]domain.AccessPoint, error) {
+	if out, err := run(ctx, "nmcli", nmcliArgs...); err == nil {
+		if accessPoints := parseNmcli(out); len(accessPoints) > 0 {
+			return accessPoints, nil
+		}
+	}
+
+	out, err := run(ctx, "iw", "dev")
+	if err != nil {
+		log.Printf("could not list wireless interfaces, is NetworkManager or iw installed? %s", err.Error())
+		return nil, err
+	}
+
+	var accessPoints []domain.AccessPoint
+	for _, iface := range parseIwInterfaces(out) {
+		scan, err := run(ctx, "iw", "dev", iface, "scan")
+		if err != nil {
+			log.Printf("could not scan %s, scanning with iw needs root: %s", iface, err.Error())
+			continue
+		}
+		accessPoints = append(accessPoints, parseIwScan(scan)...)
+	}
+	return accessPoints, nil
+}
@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package wifi
+
+import (
+	"context"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+type unsupported struct{}
+
+// NewScanner returns the Scanner of this system
+func NewScanner() Scanner {
+	return unsupported{}
+}
+
+func (unsupported) Scan(ctx context.Context) ([]domain.AccessPoint, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,23 @@
+package wifi
+
+import (
+	"context"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// windows scans with netsh, which only lists what the interface saw lately
+type windows struct{}
+
+// NewScanner returns the Scanner of this system
+func NewScanner() Scanner {
+	return windows{}
+}
+
+func (windows) Scan(ctx context.Context) ([]domain.AccessPoint, error) {
+	out, err := run(ctx, "netsh", "wlan", "show", "networks", "mode=bssid")
+	if err != nil {
+		return nil, err
+	}
+	return parseNetsh(out), nil
+}
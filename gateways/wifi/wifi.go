@@ -0,0 +1,98 @@
+// Package wifi scans the Wi-Fi access points around, with the tools each
+// operating system provides, or replays a scan captured earlier
+package wifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// ErrUnsupported error sent when scanning isn't supported on this system
+var ErrUnsupported = errors.New("wi-fi scanning is not supported on this system")
+
+// Scanner lists the access points in range
+type Scanner interface {
+	Scan(ctx context.Context) ([]domain.AccessPoint, error)
+}
+
+// Format of a scan output
+type Format string
+
+// Formats a scan can be parsed from
+const (
+	NmcliFormat   = Format("nmcli")
+	IwFormat      = Format("iw")
+	AirportFormat = Format("airport")
+	NetshFormat   = Format("netsh")
+	JSONFormat    = Format("json")
+)
+
+// Parse returns the access points in the output of a scan
+func Parse(format Format, out string) ([]domain.AccessPoint, error) {
+	switch format {
+	case NmcliFormat:
+		return parseNmcli(out), nil
+	case IwFormat:
+		return parseIwScan(out), nil
+	case AirportFormat:
+		return parseAirport(out), nil
+	case NetshFormat:
+		return parseNetsh(out), nil
+	case JSONFormat:
+		return parseJSON(out)
+	}
+	return nil, errors.New("unknown scan format " + string(format))
+}
+
+// run returns the output of a command, killed when ctx is done
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), err.Error())
+	}
+	return string(out), nil
+}
+
+// channelOf returns the Wi-Fi channel of a frequency in MHz, 0 when unknown
+func channelOf(freq int) int {
+	switch {
+	case freq == 2484:
+		return 14
+	case freq >= 2412 && freq <= 2472:
+		return (freq - 2407) / 5
+	case freq >= 5160 && freq <= 5885:
+		return (freq - 5000) / 5
+	case freq >= 5955 && freq <= 7115:
+		return (freq - 5950) / 5
+	}
+	return 0
+}
+
+// frequencyOf returns the frequency in MHz of a 2.4 or 5GHz channel, 0 when unknown
+func frequencyOf(channel int) int {
+	switch {
+	case channel == 14:
+		return 2484
+	case channel >= 1 && channel <= 13:
+		return 2407 + channel*5
+	case channel >= 32 && channel <= 177:
+		return 5000 + channel*5
+	}
+	return 0
+}
+
+// dBmOf converts a signal quality percentage, as nmcli and netsh report it, to dBm
+func dBmOf(quality int) int {
+	if quality <= 0 {
+		return -100
+	}
+	if quality >= 100 {
+		return -50
+	}
+	return quality/2 - 100
+}
@@ -0,0 +1,95 @@
+package wifi
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+
+	. "github.com/onsi/gomega"
+)
+
+func fixture(t *testing.T, name string) string {
+	data, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseNmcli(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(parseNmcli(fixture(t, "nmcli.txt"))).To(Equal([]domain.AccessPoint{
+		{BSSID: "68:ec:c5:c7:d9:f4", SSID: "Escritorio", Channel: 11, Frequency: 2462, SignalStrength: -57},
+		{BSSID: "d8:07:b6:3a:11:90", SSID: "Vizinho", Channel: 1, Frequency: 2412, SignalStrength: -64},
+		{BSSID: "d8:07:b6:3a:11:91", SSID: "Vizinho:5G", Channel: 36, Frequency: 5180, SignalStrength: -68},
+		{BSSID: "f4:f2:6d:12:ab:01", Channel: 6, Frequency: 2437, SignalStrength: -80},
+	}))
+	Expect(parseNmcli("Error: Wi-Fi radio is disabled\n")).To(BeEmpty())
+}
+
+func TestParseIwScan(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(parseIwInterfaces(fixture(t, "iw_dev.txt"))).To(Equal([]string{"wlp2s0"}))
+	Expect(parseIwScan(fixture(t, "iw_scan.txt"))).To(Equal([]domain.AccessPoint{
+		{BSSID: "68:ec:c5:c7:d9:f4", SSID: "Escritorio", Channel: 11, Frequency: 2462, SignalStrength: -23, Age: 12 * time.Millisecond},
+		{BSSID: "d8:07:b6:3a:11:91", SSID: "Vizinho_5G", Channel: 36, Frequency: 5180, SignalStrength: -64, Age: 198 * time.Millisecond},
+		{BSSID: "f4:f2:6d:12:ab:01", Channel: 6, Frequency: 2437, SignalStrength: -81},
+	}))
+}
+
+func TestParseAirport(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(parseAirport(fixture(t, "airport.txt"))).To(Equal([]domain.AccessPoint{
+		{BSSID: "68:ec:c5:c7:d9:f4", SSID: "Escritorio", Channel: 11, Frequency: 2462, SignalStrength: -43},
+		{BSSID: "d8:07:b6:3a:11:91", SSID: "Vizinho 5G", Channel: 36, Frequency: 5180, SignalStrength: -64},
+		{BSSID: "f4:f2:6d:12:ab:01", SSID: "MEO-WiFi_nomap", Channel: 149, Frequency: 5745, SignalStrength: -81},
+	}))
+}
+
+func TestParseNetsh(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(parseNetsh(fixture(t, "netsh.txt"))).To(Equal([]domain.AccessPoint{
+		{BSSID: "68:ec:c5:c7:d9:f4", SSID: "Escritorio", Channel: 11, Frequency: 2462, SignalStrength: -50},
+		{BSSID: "68:ec:c5:c7:d9:f5", SSID: "Escritorio", Channel: 44, Frequency: 5220, SignalStrength: -70},
+		{BSSID: "f4:f2:6d:12:ab:01", Channel: 6, Frequency: 2437, SignalStrength: -90},
+	}))
+}
+
+func TestReplay(t *testing.T) {
+	RegisterTestingT(t)
+
+	replay, err := NewReplay("testdata/replay.json", JSONFormat)
+	Expect(err).NotTo(HaveOccurred())
+	accessPoints, err := replay.Scan(context.Background())
+	Expect(err).NotTo(HaveOccurred())
+	Expect(accessPoints).To(Equal([]domain.AccessPoint{
+		{BSSID: "68:ec:c5:c7:d9:f4", SSID: "Escritorio", Channel: 11, SignalStrength: -43},
+		{BSSID: "d8:07:b6:3a:11:91", SSID: "Vizinho", Channel: 36, SignalStrength: -64},
+	}))
+
+	replay, err = NewReplay("testdata/nmcli.txt", NmcliFormat)
+	Expect(err).NotTo(HaveOccurred())
+	accessPoints, _ = replay.Scan(context.Background())
+	Expect(accessPoints).To(HaveLen(4))
+
+	_, err = NewReplay("testdata/nmcli.txt", Format("wpa_cli"))
+	Expect(err).To(HaveOccurred())
+}
+
+func TestChannelOf(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(channelOf(2412)).To(Equal(1))
+	Expect(channelOf(2484)).To(Equal(14))
+	Expect(channelOf(5745)).To(Equal(149))
+	Expect(channelOf(5975)).To(Equal(5), "6GHz channels are numbered from 5950MHz")
+	Expect(channelOf(60480)).To(BeZero())
+	Expect(frequencyOf(channelOf(5180))).To(Equal(5180))
+}
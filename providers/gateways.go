@@ -1,45 +1,253 @@
 package providers
 
 import (
-	"errors"
+	"log"
 	"sync"
 
+	"github.com/romeufcrosa/where-to-eat/config"
 	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
 	"github.com/romeufcrosa/where-to-eat/domain/services"
+	"github.com/romeufcrosa/where-to-eat/gateways/cache"
 	"github.com/romeufcrosa/where-to-eat/gateways/google"
+	"github.com/romeufcrosa/where-to-eat/gateways/local"
+	"github.com/romeufcrosa/where-to-eat/gateways/nominatim"
+	"github.com/romeufcrosa/where-to-eat/gateways/osm"
+	"github.com/romeufcrosa/where-to-eat/gateways/resilience"
+	"github.com/romeufcrosa/where-to-eat/providers/internal"
+	"golang.org/x/time/rate"
 )
 
 var (
-	googleInteractor = Provider("gateways/google")
-	once             = sync.Once{}
-	locate           services.Locate
+	googleInteractor    = Provider("gateways/google")
+	osmInteractor       = Provider("gateways/osm")
+	localInteractor     = Provider("gateways/local")
+	cacheInteractor     = Provider("gateways/cache")
+	nominatimInteractor = Provider("gateways/nominatim")
+	once                = sync.Once{}
+	locate              services.Locate
+	// googleBudget outlives the gateway so reloading settings doesn't refill the quota
+	googleBudget = google.NewBudget(nil)
+	// nominatimLimiter outlives the gateway so reloading settings doesn't reset the pace
+	nominatimLimiter = rate.NewLimiter(nominatim.MaxRate, 1)
+
+	guardsMu sync.Mutex
+	// guards outlive the gateways so a reload doesn't close an open circuit
+	guards = make(map[Provider]*resilience.Guard)
 )
 
 // RegisterGatewayProviders ...
 func RegisterGatewayProviders() {
-	Register(googleInteractor, func() (provider interface{}, err error) {
-		googleGeo, err := domain.NewGoogleGeo()
+	// places sources write their searches to the cache, so it is registered
+	// first and shut down last
+	register(cacheInteractor, func() (provider interface{}, err error) {
+		cfg := settings().Cache
+
+		switch cfg.Backend {
+		case config.RedisCache:
+			return cache.NewRedis(cfg.RedisAddress), nil
+		case config.MemoryCache:
+			return cache.NewLRU(cfg.Size), nil
+		}
+		// registered without a store, so enabling the cache later is a reload
+		return disabled{}, nil
+	}, WithUpdates(SettingsUpdates(func(c config.Config) interface{} { return c.Cache })))
+
+	register(googleInteractor, func() (provider interface{}, err error) {
+		cfg := settings()
+
+		googleGeo, err := domain.NewGoogleGeo(cfg.Google.APIKey.Reveal())
 		if err != nil {
 			return nil, err
 		}
-		googleMapsGateway := google.NewGoogleGateway(googleGeo.Client)
+		googleBudget.SetLimits(google.LimitsFrom(cfg.Google.QPS, cfg.Google.DailyQuota))
+		googleMapsGateway := google.NewGoogleGateway(
+			googleGeo.Client,
+			google.WithMaxPages(cfg.Google.MaxPages),
+			google.WithBudget(googleBudget),
+			google.WithTimeouts(google.TimeoutsFrom(cfg.Google.Timeouts)),
+		)
+
+		return &googleMapsGateway, nil
+	}, WithUpdates(SettingsUpdates(func(c config.Config) interface{} { return c.Google })), DependsOn(cacheInteractor))
+
+	register(osmInteractor, func() (provider interface{}, err error) {
+		overpassGateway := osm.NewOverpassGateway(settings().OSM.Endpoint, nil)
+
+		return &overpassGateway, nil
+	}, WithUpdates(SettingsUpdates(func(c config.Config) interface{} { return c.OSM })), DependsOn(cacheInteractor))
+
+	register(nominatimInteractor, func() (provider interface{}, err error) {
+		cfg := settings().Geocoding
+		nominatimGateway := nominatim.NewGateway(
+			cfg.NominatimEndpoint,
+			nil,
+			nominatim.WithLimiter(nominatimLimiter),
+			nominatim.WithContact(cfg.NominatimUserAgent, cfg.NominatimEmail),
+		)
 
-		return googleMapsGateway, nil
-	})
+		return &nominatimGateway, nil
+	}, WithUpdates(SettingsUpdates(func(c config.Config) interface{} { return c.Geocoding })))
+
+	register(localInteractor, func() (provider interface{}, err error) {
+		return local.Open(settings().Catalogue.Path)
+	}, WithUpdates(SettingsUpdates(func(c config.Config) interface{} { return c.Catalogue })), DependsOn(cacheInteractor))
 }
 
-// GetLocator returns the geo locator
-func GetLocator() (locator services.Locate, err error) {
-	var googleProvider interface{}
+func register(name Provider, registration internal.Registration, options ...Option) {
+	if err := Register(name, registration, options...); err != nil {
+		log.Printf("Provider %s is unavailable: %s", name, err.Error())
+	}
+}
+
+// disabled stands in for a provider turned off in the settings
+type disabled struct{}
+
+// GetPlacesSource returns the places source selected in the settings
+func GetPlacesSource() (services.PlacesSource, error) {
+	provider, err := Get(Provider(settings().Places.Provider))
+	if err != nil {
+		return nil, err
+	}
+
+	source, ok := provider.(services.PlacesSource)
+	if !ok {
+		return nil, ErrUnexpectedProvider
+	}
+
+	// cached inside the guard, so answers of the fallback are never cached as
+	// the provider's
+	name := Provider(settings().Places.Provider)
+	return resilience.NewPlacesSource(cached(name, source), guardFor(name), fallbackFor(name)), nil
+}
+
+// guardFor returns the guard of the provider, replaced when the resilience
+// settings change
+func guardFor(name Provider) *resilience.Guard {
+	cfg := settings().Resilience
+	policy := resilience.Policy{
+		Attempts:         cfg.Attempts,
+		BaseDelay:        cfg.BaseDelay,
+		MaxDelay:         cfg.MaxDelay,
+		FailureThreshold: cfg.FailureThreshold,
+		Cooldown:         cfg.Cooldown,
+	}
+
+	guardsMu.Lock()
+	defer guardsMu.Unlock()
+
+	guard, ok := guards[name]
+	if !ok || guard.Policy() != policy {
+		guard = resilience.NewGuard(policy)
+		guards[name] = guard
+	}
+	return guard
+}
+
+// fallbackFor returns the places source searched while name is unavailable,
+// nil when there is none
+func fallbackFor(name Provider) services.PlacesSource {
+	fallback := Provider(settings().Places.Fallback)
+	if fallback == "" || fallback == name {
+		return nil
+	}
+
+	provider, err := Get(fallback)
+	if err != nil {
+		log.Printf("Fallback provider %s is unavailable: %s", fallback, err.Error())
+		return nil
+	}
+
+	source, _ := provider.(services.PlacesSource)
+	return source
+}
 
-	googleProvider, err = Get(googleInteractor)
+// cached returns the source of provider name with its searches cached, when a
+// cache is configured
+func cached(name Provider, source services.PlacesSource) services.PlacesSource {
+	provider, err := Get(cacheInteractor)
 	if err != nil {
-		return locator, errors.New("Provider is missing")
+		return source
+	}
+
+	store, ok := provider.(cache.Store)
+	if !ok {
+		return source
 	}
 
-	googleGateway := googleProvider.(google.GeoGateway)
+	cfg := settings().Cache
+	return cache.NewPlacesSource(source, string(name), store, cfg.TTL, cfg.Precision)
+}
+
+// Selected returns the providers searches go through with the current settings:
+// the places source, its fallback, the geocoder and the cache
+func Selected() []Provider {
+	cfg := settings()
+
+	selected := []Provider{Provider(cfg.Places.Provider)}
+	for _, name := range []Provider{Provider(cfg.Places.Fallback), Provider(cfg.Geocoding.Provider), cacheInteractor} {
+		if name != "" && !containsProvider(selected, name) {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
 
-	locator = services.NewGeolocatorWith(&googleGateway)
+func containsProvider(names []Provider, name Provider) bool {
+	for _, other := range names {
+		if other == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetGeoLocator returns the Wi-Fi geo locator, only Google provides one
+func GetGeoLocator() (services.GeoLocator, error) {
+	provider, err := Get(googleInteractor)
+	if err != nil {
+		return nil, err
+	}
+
+	locator, ok := provider.(services.GeoLocator)
+	if !ok {
+		return nil, ErrUnexpectedProvider
+	}
+
+	return resilience.NewGeoLocator(locator, guardFor(googleInteractor)), nil
+}
+
+// GetGeocoder returns the geocoder selected in the settings
+func GetGeocoder() (services.Geocoder, error) {
+	name := Provider(settings().Geocoding.Provider)
+	provider, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	geocoder, ok := provider.(services.Geocoder)
+	if !ok {
+		return nil, ErrUnexpectedProvider
+	}
+
+	return resilience.NewGeocoder(geocoder, guardFor(name)), nil
+}
+
+// GetLocator returns the geo locator, Wi-Fi geolocation is only available when
+// the Google provider is registered
+func GetLocator() (locator services.Locate, err error) {
+	places, err := GetPlacesSource()
+	if err != nil {
+		return locator, err
+	}
+
+	// Wi-Fi geolocation and geocoding are optional when searching by coordinates
+	geo, _ := GetGeoLocator()
+	geocoder, _ := GetGeocoder()
+
+	locator = services.NewGeolocatorWith(geo, places)
+	if geocoder != nil {
+		locator = locator.WithGeocoder(geocoder)
+	}
 
-	return
+	return locator, nil
 }
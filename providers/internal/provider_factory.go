@@ -19,7 +19,7 @@ type Factory struct {
 
 // ProviderFactory provides access to reloading of factories
 type ProviderFactory struct {
-	mu      sync.Mutex
+	mu      sync.RWMutex
 	factory Factory
 }
 
@@ -32,22 +32,28 @@ func NewProviderFactory(registration Registration) ProviderFactory {
 	}
 }
 
-// Register registers the given registration with the given name
-func (factory *ProviderFactory) Register() error {
-	factory.mu.Lock()
-	defer factory.mu.Unlock()
-
+// Register registers the given registration with the given name. The provider is
+// built before being swapped in, readers keep the previous one until then and
+// also when building fails. The replaced provider, if any, is returned
+func (factory *ProviderFactory) Register() (interface{}, error) {
 	provider, err := factory.factory.registration()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+
+	previous := factory.factory.provider
 	factory.factory.provider = provider
-	return nil
+	return previous, nil
 }
 
 // Load loads the given registry
 func (factory *ProviderFactory) Load() (interface{}, error) {
+	factory.mu.RLock()
+	defer factory.mu.RUnlock()
+
 	if factory.factory.provider == nil {
 		return nil, ErrProviderNotRegistered
 	}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRegisterSwapsProvider(t *testing.T) {
+	RegisterTestingT(t)
+	version := 0
+	fail := false
+	factory := NewProviderFactory(func() (interface{}, error) {
+		if fail {
+			return nil, errors.New("bad settings")
+		}
+		version++
+		return version, nil
+	})
+
+	_, err := factory.Load()
+	Expect(err).To(MatchError(ErrProviderNotRegistered))
+
+	previous, err := factory.Register()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(previous).To(BeNil())
+
+	previous, err = factory.Register()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(previous).To(Equal(1))
+	Expect(factory.Load()).To(Equal(2))
+
+	fail = true
+	_, err = factory.Register()
+	Expect(err).To(HaveOccurred())
+	Expect(factory.Load()).To(Equal(2), "a failed registration should keep the previous provider")
+}
+
+func TestConcurrentLoadAndRegister(t *testing.T) {
+	RegisterTestingT(t)
+	factory := NewProviderFactory(func() (interface{}, error) {
+		return struct{}{}, nil
+	})
+	factory.Register()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			factory.Register()
+		}()
+		go func() {
+			defer wg.Done()
+			provider, err := factory.Load()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider).NotTo(BeNil())
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retireAfter how long a replaced provider is kept open for the calls still
+// using it, longer than any request may last
+var retireAfter = time.Minute
+
+// retired replaced providers waiting to be closed, by the timer closing them
+var retired = struct {
+	sync.Mutex
+	providers map[*time.Timer]func()
+}{providers: make(map[*time.Timer]func())}
+
+// healthInterval how long the health of a provider is reported from its last
+// check, so frequent probes don't each call upstream
+var healthInterval = 30 * time.Second
+
+// checked the last health check of every provider
+var checked = struct {
+	sync.Mutex
+	checks map[Provider]check
+}{checks: make(map[Provider]check)}
+
+type check struct {
+	err error
+	at  time.Time
+}
+
+// Closer implemented by providers holding resources to release, it is called
+// on shutdown and once a provider replaced by a reload is retired
+type Closer interface {
+	Close() error
+}
+
+// HealthChecker implemented by providers able to tell whether they can serve
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// ShutdownError lists the providers that failed to close
+type ShutdownError struct {
+	Failures map[Provider]error
+}
+
+func (s ShutdownError) Error() string {
+	var failures []string
+	for name, err := range s.Failures {
+		failures = append(failures, fmt.Sprintf("%s: %s", name, err.Error()))
+	}
+	return "could not close providers: " + strings.Join(failures, "; ")
+}
+
+// Shutdown closes every provider, dependents before their dependencies. It stops
+// early when ctx is done
+func Shutdown(ctx context.Context) error {
+	manufacturer.mu.RLock()
+	defer manufacturer.mu.RUnlock()
+
+	closeRetired()
+
+	failures := make(map[Provider]error)
+	// Dependencies are registered first, reversing the order closes dependents first
+	for i := len(manufacturer.order) - 1; i >= 0; i-- {
+		name := manufacturer.order[i]
+		if err := ctx.Err(); err != nil {
+			failures[name] = err
+			continue
+		}
+
+		provider, err := manufacturer.factories[name].factory.Load()
+		if err != nil {
+			continue
+		}
+		if closer, ok := provider.(Closer); ok {
+			log.Printf("Closing provider %s", name)
+			if err := closer.Close(); err != nil {
+				failures[name] = err
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return ShutdownError{Failures: failures}
+	}
+	return nil
+}
+
+// Health checks the named providers, a nil error meaning healthy. A provider
+// that isn't registered is reported with ErrProviderNotFound. A check is
+// reused for healthInterval
+func Health(ctx context.Context, names ...Provider) map[Provider]error {
+	manufacturer.mu.RLock()
+	defer manufacturer.mu.RUnlock()
+
+	health := make(map[Provider]error)
+	for _, name := range names {
+		manufacture, ok := manufacturer.factories[name]
+		if !ok {
+			health[name] = ErrProviderNotFound
+			continue
+		}
+
+		provider, err := manufacture.factory.Load()
+		if err != nil {
+			health[name] = err
+			continue
+		}
+
+		health[name] = nil
+		if checker, ok := provider.(HealthChecker); ok {
+			health[name] = checkHealth(ctx, name, checker)
+		}
+	}
+
+	return health
+}
+
+// checkHealth returns the last check of the provider while it is recent,
+// checking it again otherwise. Checks cut short by ctx aren't kept
+func checkHealth(ctx context.Context, name Provider, checker HealthChecker) error {
+	checked.Lock()
+	defer checked.Unlock()
+
+	if last, ok := checked.checks[name]; ok && time.Since(last.at) < healthInterval {
+		return last.err
+	}
+
+	err := checker.Health(ctx)
+	if ctx.Err() == nil {
+		checked.checks[name] = check{err: err, at: time.Now()}
+	}
+	return err
+}
+
+// forgetHealth drops the last check of a provider replaced by a reload
+func forgetHealth(name Provider) {
+	checked.Lock()
+	defer checked.Unlock()
+
+	delete(checked.checks, name)
+}
+
+func closeProvider(name Provider, provider interface{}) {
+	closer, ok := provider.(Closer)
+	if !ok {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		log.Printf("Could not close replaced provider %s: %s", name, err.Error())
+	}
+}
+
+// retire closes a provider replaced by a reload once the calls that loaded it
+// before the reload had time to finish
+func retire(name Provider, provider interface{}) {
+	if _, ok := provider.(Closer); !ok {
+		return
+	}
+
+	retired.Lock()
+	defer retired.Unlock()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(retireAfter, func() {
+		retired.Lock()
+		delete(retired.providers, timer)
+		retired.Unlock()
+
+		closeProvider(name, provider)
+	})
+	retired.providers[timer] = func() { closeProvider(name, provider) }
+}
+
+// closeRetired closes the retired providers without waiting for their timers
+func closeRetired() {
+	retired.Lock()
+	defer retired.Unlock()
+
+	for timer, close := range retired.providers {
+		if timer.Stop() {
+			close()
+		}
+		delete(retired.providers, timer)
+	}
+}
@@ -2,17 +2,22 @@ package providers
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"os"
+	"reflect"
 	"sync"
 
+	"github.com/romeufcrosa/where-to-eat/config"
 	"github.com/romeufcrosa/where-to-eat/providers/internal"
-	"googlemaps.github.io/maps"
 )
 
 // ErrProviderNotFound error sent when the provider was not found
 var (
 	ErrProviderNotFound          = errors.New("provider was not found")
 	ErrProviderAlreadyRegistered = errors.New("provider already registered")
+	ErrMissingDependency         = errors.New("provider dependency is not registered")
+	ErrUnexpectedProvider        = errors.New("provider has an unexpected type")
 
 	env = os.Getenv("ENV")
 )
@@ -22,12 +27,21 @@ type Provider string
 
 // Params parameters to pass onto providers
 type Params struct {
-	client *maps.Client
+	config func() config.Config
 }
 
-// NewParams returns a new Params
-func NewParams(client *maps.Client) Params {
-	return Params{client: client}
+// NewParams returns a new Params reading the settings through current, which
+// is called on every registration so reloaded settings are picked up
+func NewParams(current func() config.Config) Params {
+	return Params{config: current}
+}
+
+// settings returns the configured settings, or the defaults when unconfigured
+func settings() config.Config {
+	if manufacturer.params.config == nil {
+		return config.Defaults()
+	}
+	return manufacturer.params.config()
 }
 
 // ConfigurationUpdateFunc a function that checks if the configurations have changed
@@ -36,6 +50,7 @@ type ConfigurationUpdateFunc func() bool
 type manufactureFactory struct {
 	factory    *internal.ProviderFactory
 	hasUpdates ConfigurationUpdateFunc
+	dependsOn  []Provider
 }
 
 type manufacture struct {
@@ -43,6 +58,7 @@ type manufacture struct {
 	mu           sync.RWMutex
 	hasUpdates   ConfigurationUpdateFunc
 	factories    map[Provider]manufactureFactory
+	order        []Provider
 	params       Params
 }
 
@@ -50,6 +66,26 @@ func noUpdates() bool {
 	return false
 }
 
+// SettingsUpdates returns a ConfigurationUpdateFunc reporting a change whenever
+// the part of the settings picked by section differs from when it last looked,
+// so a provider is only rebuilt when its own settings change
+func SettingsUpdates(section func(config.Config) interface{}) ConfigurationUpdateFunc {
+	var mu sync.Mutex
+	last := section(settings())
+
+	return func() bool {
+		current := section(settings())
+
+		mu.Lock()
+		defer mu.Unlock()
+		if reflect.DeepEqual(current, last) {
+			return false
+		}
+		last = current
+		return true
+	}
+}
+
 var (
 	manufacturer = manufacture{
 		isConfigured: false,
@@ -85,35 +121,67 @@ func Get(name Provider) (interface{}, error) {
 	}
 
 	if hasUpdates() {
-		err := manufacture.factory.Register()
+		previous, err := manufacture.factory.Register()
 		if err != nil {
-			return nil, err
+			log.Printf("Could not reload provider %s, keeping the previous one: %s", name, err.Error())
+		} else {
+			log.Printf("Reloaded provider %s", name)
+			// calls that loaded the previous provider may still be using it
+			retire(name, previous)
+			forgetHealth(name)
 		}
 	}
 
 	return manufacture.factory.Load()
 }
 
+// Option configures how a provider is registered
+type Option func(*manufactureFactory)
+
+// WithUpdates rebuilds the provider whenever updates reports a change, instead
+// of relying on the ConfigurationUpdateFunc given to Configure
+func WithUpdates(updates ConfigurationUpdateFunc) Option {
+	return func(m *manufactureFactory) {
+		m.hasUpdates = updates
+	}
+}
+
+// DependsOn declares providers that must be registered first, and are shut
+// down after this one
+func DependsOn(names ...Provider) Option {
+	return func(m *manufactureFactory) {
+		m.dependsOn = append(m.dependsOn, names...)
+	}
+}
+
 // Register registers a new provider with the given name
-func Register(name Provider, registration internal.Registration, update ...ConfigurationUpdateFunc) error {
+func Register(name Provider, registration internal.Registration, options ...Option) error {
+	manufacturer.mu.Lock()
+	defer manufacturer.mu.Unlock()
+
 	if _, found := manufacturer.factories[name]; found {
 		return ErrProviderAlreadyRegistered
 	}
 
 	factory := internal.NewProviderFactory(registration)
-	if err := factory.Register(); err != nil {
-		// log.WithError(err).Error(context.TODO(), "could not register provider")
-		return err
+	manufacture := manufactureFactory{
+		factory: &factory,
+	}
+	for _, option := range options {
+		option(&manufacture)
 	}
 
-	var updateFunc ConfigurationUpdateFunc
-	if len(update) > 0 {
-		updateFunc = update[0]
+	for _, dependency := range manufacture.dependsOn {
+		if _, found := manufacturer.factories[dependency]; !found {
+			return fmt.Errorf("%s: %s needs %s", ErrMissingDependency.Error(), name, dependency)
+		}
 	}
 
-	manufacturer.factories[name] = manufactureFactory{
-		factory:    &factory,
-		hasUpdates: updateFunc,
+	if _, err := factory.Register(); err != nil {
+		return err
 	}
+
+	manufacturer.factories[name] = manufacture
+	manufacturer.order = append(manufacturer.order, name)
 	return nil
 }
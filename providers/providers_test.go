@@ -0,0 +1,181 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/romeufcrosa/where-to-eat/config"
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+	"github.com/romeufcrosa/where-to-eat/gateways/cache"
+	"github.com/romeufcrosa/where-to-eat/gateways/resilience"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestGetReloadsOnlyAffectedProviders(t *testing.T) {
+	RegisterTestingT(t)
+	env = "tests"
+
+	current := config.Defaults()
+	Configure(NewParams(func() config.Config { return current }), noUpdates)
+
+	builds := map[Provider]int{}
+	register := func(name Provider, section func(config.Config) interface{}) {
+		err := Register(name, func() (interface{}, error) {
+			builds[name]++
+			return builds[name], nil
+		}, WithUpdates(SettingsUpdates(section)))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	register("tests/osm", func(c config.Config) interface{} { return c.OSM })
+	register("tests/catalogue", func(c config.Config) interface{} { return c.Catalogue })
+
+	current.OSM.Endpoint = "http://localhost:12345/api/interpreter"
+
+	Expect(Get("tests/osm")).To(Equal(2), "the OSM provider should be rebuilt")
+	Expect(Get("tests/osm")).To(Equal(2), "only once per change")
+	Expect(Get("tests/catalogue")).To(Equal(1), "the catalogue settings didn't change")
+}
+
+type closingProvider struct {
+	name   string
+	closed *[]string
+}
+
+func (c closingProvider) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return nil
+}
+
+func TestShutdownClosesDependentsFirst(t *testing.T) {
+	RegisterTestingT(t)
+	var closed []string
+
+	provide := func(name string) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			return closingProvider{name: name, closed: &closed}, nil
+		}
+	}
+
+	Expect(Register("tests/client", provide("client"))).To(Succeed())
+	Expect(Register("tests/cache", provide("cache"), DependsOn("tests/client"))).To(Succeed())
+	Expect(Register("tests/orphan", provide("orphan"), DependsOn("tests/missing"))).To(MatchError(ContainSubstring(ErrMissingDependency.Error())))
+
+	Expect(Shutdown(context.Background())).To(Succeed())
+	Expect(closed).To(Equal([]string{"cache", "client"}))
+}
+
+func TestGetRetiresReplacedProviders(t *testing.T) {
+	RegisterTestingT(t)
+	env = "tests"
+	defer func(after time.Duration) { retireAfter = after }(retireAfter)
+	retireAfter = time.Hour
+
+	current := config.Defaults()
+	Configure(NewParams(func() config.Config { return current }), noUpdates)
+
+	var closed []string
+	builds := 0
+	Expect(Register("tests/redis", func() (interface{}, error) {
+		builds++
+		return closingProvider{name: fmt.Sprintf("redis-%d", builds), closed: &closed}, nil
+	}, WithUpdates(SettingsUpdates(func(c config.Config) interface{} { return c.Cache })))).To(Succeed())
+
+	current.Cache.TTL = time.Hour
+	Expect(Get("tests/redis")).To(Equal(closingProvider{name: "redis-2", closed: &closed}))
+	Expect(closed).To(BeEmpty(), "calls may still be using the replaced provider")
+
+	closeRetired()
+	Expect(closed).To(Equal([]string{"redis-1"}))
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Health(ctx context.Context) error {
+	return errors.New("unreachable")
+}
+
+func TestHealthChecksOnlyTheNamedProviders(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(Register("tests/selected", func() (interface{}, error) { return 1, nil })).To(Succeed())
+	Expect(Register("tests/unselected", func() (interface{}, error) { return failingProvider{}, nil })).To(Succeed())
+
+	health := Health(context.Background(), "tests/selected", "tests/unregistered")
+	Expect(health).To(HaveLen(2))
+	Expect(health["tests/selected"]).To(BeNil())
+	Expect(health["tests/unregistered"]).To(MatchError(ErrProviderNotFound))
+}
+
+// countedProvider counts its health checks
+type countedProvider struct {
+	checks *int
+}
+
+func (c countedProvider) Health(ctx context.Context) error {
+	*c.checks++
+	return nil
+}
+
+func TestHealthIsCheckedOncePerInterval(t *testing.T) {
+	RegisterTestingT(t)
+	defer func(interval time.Duration) { healthInterval = interval }(healthInterval)
+	healthInterval = time.Hour
+
+	checks := 0
+	Expect(Register("tests/probed", func() (interface{}, error) { return countedProvider{checks: &checks}, nil })).To(Succeed())
+
+	for i := 0; i < 3; i++ {
+		Expect(Health(context.Background(), "tests/probed")["tests/probed"]).To(BeNil())
+	}
+	Expect(checks).To(Equal(1), "probes within the interval shouldn't call upstream")
+
+	forgetHealth("tests/probed")
+	Health(context.Background(), "tests/probed")
+	Expect(checks).To(Equal(2), "a reloaded provider is checked again")
+}
+
+// placesSource answers searches with a place named after it, or down when it is
+type placesSource struct {
+	name string
+	down *bool
+}
+
+func (p placesSource) ListRestaurants(ctx context.Context, req domain.SearchRequest) ([]domain.Place, error) {
+	if p.down != nil && *p.down {
+		return nil, domain.NewError(domain.UpstreamUnavailable, p.name+" is down")
+	}
+	return []domain.Place{{ID: p.name}}, nil
+}
+
+func (p placesSource) PlaceDetails(ctx context.Context, placeID string) (domain.Place, error) {
+	return domain.Place{ID: placeID}, nil
+}
+
+func TestFallbackAnswersAreNotCached(t *testing.T) {
+	RegisterTestingT(t)
+	env = "tests"
+
+	current := config.Defaults()
+	current.Places = config.Places{Provider: "tests/primary", Fallback: "tests/fallback"}
+	current.Resilience.Attempts = 1
+	Configure(NewParams(func() config.Config { return current }), noUpdates)
+
+	down := true
+	Expect(Register(cacheInteractor, func() (interface{}, error) { return cache.NewLRU(8), nil })).To(Succeed())
+	Expect(Register("tests/primary", func() (interface{}, error) { return placesSource{name: "primary", down: &down}, nil })).To(Succeed())
+	Expect(Register("tests/fallback", func() (interface{}, error) { return placesSource{name: "fallback"}, nil })).To(Succeed())
+
+	request := domain.SearchRequest{Lat: 38.7107, Lng: -9.1365, Distance: 500}
+	source, err := GetPlacesSource()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(source.ListRestaurants(context.Background(), request)).To(Equal([]domain.Place{{ID: "fallback"}}))
+
+	down = false
+	guards = make(map[Provider]*resilience.Guard)
+	source, err = GetPlacesSource()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(source.ListRestaurants(context.Background(), request)).To(Equal([]domain.Place{{ID: "primary"}}), "the fallback answer shouldn't have been cached")
+}
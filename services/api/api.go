@@ -1,6 +1,8 @@
 package api
 
 import (
+	"expvar"
+	"fmt"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -14,7 +16,33 @@ func Router() http.Handler {
 	router := httprouter.New()
 
 	router.POST("/api/v1/restaurants", v1.FindWhereToEat(params))
-	router.ServeFiles("/*filepath", http.Dir("./web"))
+	router.GET("/api/v1/restaurants", v1.FindWhereToEatByQuery(params))
+	router.GET("/api/health", v1.Health(params))
+	router.Handler("GET", "/debug/vars", metrics("google_quota_remaining", "google_calls"))
+	// a catch-all route would conflict with the GET ones, unknown paths are static files instead
+	router.NotFound = http.HandlerFunc(http.FileServer(http.Dir("./web")).ServeHTTP)
 
 	return router
 }
+
+// metrics serves only the named vars, expvar's own handler also publishes the
+// command line and with it any API key given as a flag
+func metrics(names ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, "{")
+		first := true
+		for _, name := range names {
+			value := expvar.Get(name)
+			if value == nil {
+				continue
+			}
+			if !first {
+				fmt.Fprint(w, ",")
+			}
+			first = false
+			fmt.Fprintf(w, "\n%q: %s", name, value.String())
+		}
+		fmt.Fprint(w, "\n}\n")
+	})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRouter(t *testing.T) {
+	RegisterTestingT(t)
+	router := Router()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"), "the health report should be served")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	Expect(recorder.Code).To(Equal(http.StatusOK))
+	vars := map[string]json.RawMessage{}
+	Expect(json.Unmarshal(recorder.Body.Bytes(), &vars)).To(Succeed())
+	Expect(vars).NotTo(HaveKey("cmdline"), "flags can carry API keys")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/restaurants?lat=91&lng=-9.13", nil))
+	Expect(recorder.Code).To(Equal(http.StatusBadRequest), "searches can be made with query parameters")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing.html", nil))
+	Expect(recorder.Code).To(Equal(http.StatusNotFound), "unknown paths should be looked up as static files")
+}
+
+func TestMetrics(t *testing.T) {
+	RegisterTestingT(t)
+	expvar.NewInt("test_metric").Set(3)
+
+	recorder := httptest.NewRecorder()
+	metrics("test_metric", "missing_metric").ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	vars := map[string]json.RawMessage{}
+	Expect(json.Unmarshal(recorder.Body.Bytes(), &vars)).To(Succeed())
+	Expect(vars).To(HaveLen(1))
+	Expect(string(vars["test_metric"])).To(Equal("3"))
+}
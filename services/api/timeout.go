@@ -0,0 +1,18 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithTimeout bounds the context of every request by timeout, so upstream calls
+// made on its behalf are cancelled once the client can no longer be answered
+func WithTimeout(handler http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
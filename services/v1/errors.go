@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+)
+
+// Error codes sent in ResultError, stable for clients to rely on
+const (
+	CodeInternal            = 1
+	CodeQuotaExceeded       = 2
+	CodeValidation          = 3
+	CodeNotFound            = 4
+	CodeUpstreamUnavailable = 5
+	CodeTimeout             = 6
+)
+
+// timeout reason of requests running past their deadline
+const timeout = "timeout"
+
+// problemMediaType RFC 7807 media type, sent to clients accepting it
+const problemMediaType = "application/problem+json"
+
+// problemTypePrefix identifies the problem types, one per reason
+const problemTypePrefix = "urn:where-to-eat:problem:"
+
+// failure how an error is reported to API clients
+type failure struct {
+	status int
+	code   int
+	reason string
+}
+
+var failures = map[domain.ErrorKind]failure{
+	domain.Validation:          {http.StatusBadRequest, CodeValidation, string(domain.Validation)},
+	domain.NotFound:            {http.StatusNotFound, CodeNotFound, string(domain.NotFound)},
+	domain.UpstreamUnavailable: {http.StatusServiceUnavailable, CodeUpstreamUnavailable, string(domain.UpstreamUnavailable)},
+	domain.QuotaExceeded:       {http.StatusTooManyRequests, CodeQuotaExceeded, string(domain.QuotaExceeded)},
+}
+
+// failureOf returns how err is reported, a request past its deadline being a timeout
+func failureOf(ctx context.Context, err error) failure {
+	if ctx.Err() == context.DeadlineExceeded || err == context.DeadlineExceeded {
+		return failure{http.StatusGatewayTimeout, CodeTimeout, timeout}
+	}
+
+	if f, ok := failures[domain.KindOf(err)]; ok {
+		return f
+	}
+	return failure{http.StatusInternalServerError, CodeInternal, string(domain.Unknown)}
+}
+
+// Problem RFC 7807 problem details, along with the error code
+type Problem struct {
+	Type          string              `json:"type"`
+	Title         string              `json:"title"`
+	Status        int                 `json:"status"`
+	Detail        string              `json:"detail"`
+	Code          int                 `json:"code"`
+	InvalidParams []domain.FieldError `json:"invalid-params,omitempty"`
+	Candidates    []domain.Match      `json:"candidates,omitempty"`
+}
+
+type formatKey struct{}
+
+// requestContext returns the context of req, remembering whether the client
+// prefers errors as problem details
+func requestContext(req *http.Request) context.Context {
+	return context.WithValue(req.Context(), formatKey{}, strings.Contains(req.Header.Get("Accept"), problemMediaType))
+}
+
+func wantsProblem(ctx context.Context) bool {
+	problem, _ := ctx.Value(formatKey{}).(bool)
+	return problem
+}
+
+// fieldsOf returns the field errors of a validation error
+func fieldsOf(err error) []domain.FieldError {
+	switch err := err.(type) {
+	case domain.ValidationError:
+		return err.Fields
+	case interface{ Fields() []domain.FieldError }:
+		return err.Fields()
+	}
+	return nil
+}
+
+// candidatesOf returns the places an ambiguous address could be
+func candidatesOf(err error) []domain.Match {
+	if ambiguous, ok := err.(domain.AmbiguousError); ok {
+		return ambiguous.Candidates
+	}
+	return nil
+}
+
+// Error sends an error with the status, code and reason its kind maps to
+func Error(ctx context.Context, w http.ResponseWriter, err error) {
+	f := failureOf(ctx, err)
+
+	if wantsProblem(ctx) {
+		problem, _ := json.Marshal(Problem{
+			Type:          problemTypePrefix + f.reason,
+			Title:         http.StatusText(f.status),
+			Status:        f.status,
+			Detail:        err.Error(),
+			Code:          f.code,
+			InvalidParams: fieldsOf(err),
+			Candidates:    candidatesOf(err),
+		})
+
+		w.Header().Add("Content-Type", problemMediaType)
+		w.WriteHeader(f.status)
+		fmt.Fprint(w, string(problem))
+		return
+	}
+
+	result, _ := json.Marshal(Result{
+		Error: &ResultError{
+			Code:       f.code,
+			Reason:     f.reason,
+			Message:    err.Error(),
+			Fields:     fieldsOf(err),
+			Candidates: candidatesOf(err),
+		},
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(f.status)
+	fmt.Fprint(w, string(result))
+}
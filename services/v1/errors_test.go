@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestError(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	testCases := []struct {
+		desc           string
+		ctx            context.Context
+		err            error
+		expectedStatus int
+		expectedCode   int
+		expectedReason string
+	}{
+		{
+			desc:           "Bad request",
+			ctx:            context.Background(),
+			err:            domain.ErrInvalidPricing,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeValidation,
+			expectedReason: "validation",
+		},
+		{
+			desc:           "Nothing found",
+			ctx:            context.Background(),
+			err:            domain.NewError(domain.NotFound, "no suitable place found"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   CodeNotFound,
+			expectedReason: "not_found",
+		},
+		{
+			desc:           "Upstream down",
+			ctx:            context.Background(),
+			err:            domain.WrapError(domain.UpstreamUnavailable, errors.New("maps: UNKNOWN_ERROR - ")),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   CodeUpstreamUnavailable,
+			expectedReason: "upstream_unavailable",
+		},
+		{
+			desc:           "Quota spent",
+			ctx:            context.Background(),
+			err:            domain.ErrQuotaExceeded,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   CodeQuotaExceeded,
+			expectedReason: "quota_exceeded",
+		},
+		{
+			desc:           "Past the request deadline",
+			ctx:            expired,
+			err:            errors.New("Get https://maps.googleapis.com: context deadline exceeded"),
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedCode:   CodeTimeout,
+			expectedReason: "timeout",
+		},
+		{
+			desc:           "Anything else",
+			ctx:            context.Background(),
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   CodeInternal,
+			expectedReason: "internal",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			RegisterTestingT(t)
+			recorder := httptest.NewRecorder()
+
+			Error(tC.ctx, recorder, tC.err)
+
+			var result Result
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &result)).To(Succeed())
+			Expect(recorder.Code).To(Equal(tC.expectedStatus))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(result.Error.Code).To(Equal(tC.expectedCode))
+			Expect(result.Error.Reason).To(Equal(tC.expectedReason))
+			Expect(result.Error.Message).To(Equal(tC.err.Error()))
+		})
+	}
+}
+
+func TestErrorAsProblemDetails(t *testing.T) {
+	RegisterTestingT(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/restaurants", nil)
+	req.Header.Set("Accept", "application/problem+json, application/json")
+	recorder := httptest.NewRecorder()
+
+	Error(requestContext(req), recorder, domain.ErrInvalidPricing)
+
+	var problem Problem
+	Expect(json.Unmarshal(recorder.Body.Bytes(), &problem)).To(Succeed())
+	Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+	Expect(problem).To(Equal(Problem{
+		Type:   "urn:where-to-eat:problem:validation",
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: domain.ErrInvalidPricing.Error(),
+		Code:   CodeValidation,
+	}))
+}
+
+func TestErrorListsAmbiguousCandidates(t *testing.T) {
+	RegisterTestingT(t)
+	candidates := []domain.Match{
+		{Address: "Rua Augusta, 1100-053 Lisboa", Position: domain.Position{Location: domain.Location{Lat: 38.7107, Lng: -9.1365}}},
+		{Address: "Rua Augusta, 2610-158 Amadora", Position: domain.Position{Location: domain.Location{Lat: 38.7526, Lng: -9.2289}}},
+	}
+	recorder := httptest.NewRecorder()
+
+	Error(context.Background(), recorder, domain.AmbiguousError{Field: "near", Query: "Rua Augusta", Candidates: candidates})
+
+	var result Result
+	Expect(json.Unmarshal(recorder.Body.Bytes(), &result)).To(Succeed())
+	Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	Expect(result.Error.Fields).To(Equal([]domain.FieldError{{Field: "near", Problem: "matches 2 places, be more specific"}}))
+	Expect(result.Error.Candidates).To(Equal(candidates))
+}
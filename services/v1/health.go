@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/romeufcrosa/where-to-eat/providers"
+)
+
+// HealthReport the health of every provider, "ok" or the reason it is failing
+type HealthReport struct {
+	Status    string            `json:"status"`
+	Providers map[string]string `json:"providers"`
+}
+
+// Health controller reporting whether the providers searches go through can serve
+func Health(params Params) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		report := HealthReport{
+			Status:    "ok",
+			Providers: make(map[string]string),
+		}
+		status := http.StatusOK
+
+		for name, err := range providers.Health(req.Context(), providers.Selected()...) {
+			report.Providers[string(name)] = "ok"
+			if err != nil {
+				report.Providers[string(name)] = err.Error()
+				report.Status = "degraded"
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		response, _ := json.Marshal(report)
+
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, string(response))
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	domain "github.com/romeufcrosa/where-to-eat/domain/entities"
 )
 
 // Params params to enter in controller
@@ -17,8 +19,12 @@ type Jsonable interface {
 
 // ResultError contains the error code and message
 type ResultError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int                 `json:"code"`
+	Reason  string              `json:"reason"`
+	Message string              `json:"message"`
+	Fields  []domain.FieldError `json:"fields,omitempty"`
+	// Candidates the places an ambiguous address could be
+	Candidates []domain.Match `json:"candidates,omitempty"`
 }
 
 // Result the final result for a given message
@@ -27,18 +33,33 @@ type Result struct {
 	Result json.RawMessage `json:"result,omitempty"`
 }
 
-// Error sends an error
-func Error(ctx context.Context, w http.ResponseWriter, err error) {
-	result, _ := json.Marshal(Result{
-		Error: &ResultError{
-			Code:    1,
-			Message: err.Error(),
-		},
-	})
+// List envelope for endpoints returning several results
+type List struct {
+	Count int             `json:"count"`
+	Items json.RawMessage `json:"items"`
+}
 
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprint(w, string(result))
+// ListOf wraps a Jsonable collection of count items into a List
+func ListOf(count int, items Jsonable) Jsonable {
+	return listOf{count: count, items: items}
+}
+
+type listOf struct {
+	count int
+	items Jsonable
+}
+
+// ToJSON returns a JSON representation of the List envelope
+func (l listOf) ToJSON() (json.RawMessage, error) {
+	items, err := l.items.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(List{
+		Count: l.count,
+		Items: items,
+	})
 }
 
 // Response sends a response
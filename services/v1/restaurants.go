@@ -11,27 +11,60 @@ import (
 	"github.com/romeufcrosa/where-to-eat/providers"
 )
 
-// FindWhereToEat controller to get a random restaurant
+var errMissingBody = domain.NewError(domain.Validation, "request body is required")
+
+// binder reads the search out of a request
+type binder func(req *http.Request) (domain.SearchRequest, error)
+
+func bindJSON(req *http.Request) (domain.SearchRequest, error) {
+	jsonPayload, err := bodyBytes(req)
+	if err != nil {
+		return domain.SearchRequest{}, err
+	}
+
+	return domain.NewFromJSON(jsonPayload)
+}
+
+func bindQuery(req *http.Request) (domain.SearchRequest, error) {
+	return domain.NewFromQuery(req.URL.Query())
+}
+
+// FindWhereToEat controller to get a random restaurant, searched for in the JSON body
 func FindWhereToEat(params Params) httprouter.Handle {
+	return findWhereToEat(bindJSON)
+}
+
+// FindWhereToEatByQuery controller to get a random restaurant, searched for in
+// the query parameters so searches can be shared as links
+func FindWhereToEatByQuery(params Params) httprouter.Handle {
+	return findWhereToEat(bindQuery)
+}
+
+func findWhereToEat(bind binder) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
-		ctx := req.Context()
+		ctx := requestContext(req)
 
-		jsonPayload, err := bodyBytes(req)
+		searchRequest, err := bind(req)
 		if err != nil {
 			Error(ctx, w, err)
 			return
 		}
+		fmt.Printf("Received request: %v\n", searchRequest)
 
-		searchRequest, err := domain.NewFromJSON(jsonPayload)
+		interactor, err := providers.GetLocator()
 		if err != nil {
 			Error(ctx, w, err)
 			return
 		}
-		fmt.Printf("Received request: %v\n", searchRequest)
 
-		interactor, err := providers.GetLocator()
-		if err != nil {
-			Error(ctx, w, err)
+		if searchRequest.Count > 0 {
+			shortlist, err := interactor.FetchRestaurants(ctx, searchRequest)
+			if err != nil {
+				Error(ctx, w, err)
+				return
+			}
+
+			Response(ctx, w, ListOf(len(shortlist), domain.Places(shortlist)))
 			return
 		}
 
@@ -49,8 +82,7 @@ func bodyBytes(r *http.Request) ([]byte, error) {
 	var bodyBytes []byte
 
 	if r.Body == nil {
-		// FIXME: Second param should be a error type
-		return nil, nil
+		return nil, errMissingBody
 	}
 
 	bodyBytes, err := ioutil.ReadAll(r.Body)
@@ -2,7 +2,6 @@
 package mocks
 
 import context "context"
-import entities "github.com/romeufcrosa/where-to-eat/domain/entities"
 import maps "googlemaps.github.io/maps"
 import mock "github.com/stretchr/testify/mock"
 
@@ -33,45 +32,3 @@ func (_m *GeoLocator) Geolocate(ctx context.Context, accessPoints []maps.WiFiAcc
 
 	return r0, r1
 }
-
-// ListRestaurants provides a mock function with given fields: ctx, searchRequest
-func (_m *GeoLocator) ListRestaurants(ctx context.Context, searchRequest *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
-	ret := _m.Called(ctx, searchRequest)
-
-	var r0 maps.PlacesSearchResponse
-	if rf, ok := ret.Get(0).(func(context.Context, *maps.NearbySearchRequest) maps.PlacesSearchResponse); ok {
-		r0 = rf(ctx, searchRequest)
-	} else {
-		r0 = ret.Get(0).(maps.PlacesSearchResponse)
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *maps.NearbySearchRequest) error); ok {
-		r1 = rf(ctx, searchRequest)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// PlaceDetails provides a mock function with given fields: ctx, detailsRequest
-func (_m *GeoLocator) PlaceDetails(ctx context.Context, detailsRequest *maps.PlaceDetailsRequest) (entities.Place, error) {
-	ret := _m.Called(ctx, detailsRequest)
-
-	var r0 entities.Place
-	if rf, ok := ret.Get(0).(func(context.Context, *maps.PlaceDetailsRequest) entities.Place); ok {
-		r0 = rf(ctx, detailsRequest)
-	} else {
-		r0 = ret.Get(0).(entities.Place)
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *maps.PlaceDetailsRequest) error); ok {
-		r1 = rf(ctx, detailsRequest)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}